@@ -11,6 +11,13 @@ import (
 	"github.com/hyperhq/libcompose/yaml"
 )
 
+// hashIgnoredKeys holds the service configuration keys that do not change how
+// the containers are created, so changing them does not recreate them.
+var hashIgnoredKeys = map[string]bool{
+	"UpdateConfig": true,
+	"Scale":        true,
+}
+
 // GetServiceHash computes and returns a hash that will identify a service.
 // This hash will be then used to detect if the service definition/configuration
 // have changed and needs to be recreated.
@@ -33,6 +40,16 @@ func GetServiceHash(name string, config *ServiceConfig) string {
 		valueField := val.Field(i)
 		keyField := val.Type().Field(i)
 
+		if hashIgnoredKeys[keyField.Name] {
+			continue
+		}
+
+		// Services without a healthcheck keep the hash they had before the
+		// key existed, so their containers are not recreated.
+		if h, ok := valueField.Interface().(*HealthCheck); ok && h == nil {
+			continue
+		}
+
 		serviceKeys = append(serviceKeys, keyField.Name)
 		unsortedKeyValue[keyField.Name] = valueField.Interface()
 	}
@@ -79,6 +96,22 @@ func GetServiceHash(name string, config *ServiceConfig) string {
 			for _, sliceKey := range s {
 				io.WriteString(hash, fmt.Sprintf("%s, ", sliceKey))
 			}
+		case yaml.DependsOn:
+			sliceKeys := []string{}
+			for _, dependency := range s {
+				if dependency.Condition == "" {
+					sliceKeys = append(sliceKeys, dependency.Service)
+				} else {
+					sliceKeys = append(sliceKeys, dependency.Service+":"+dependency.Condition)
+				}
+			}
+			sort.Strings(sliceKeys)
+
+			for _, sliceKey := range sliceKeys {
+				io.WriteString(hash, fmt.Sprintf("%s, ", sliceKey))
+			}
+		case *HealthCheck:
+			io.WriteString(hash, fmt.Sprintf("%v", *s))
 		case []string:
 			sliceKeys := s
 			sort.Strings(sliceKeys)
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/hyperhq/libcompose/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func hashBaselineConfig() *ServiceConfig {
+	return &ServiceConfig{
+		Image:       "nginx:latest",
+		Command:     yaml.Command{"nginx", "-g", "daemon off;"},
+		DependsOn:   yaml.DependsOn{{Service: "db"}},
+		Environment: yaml.MaporEqualSlice{"A=1"},
+		Labels:      yaml.SliceorMap{"com.example": "web"},
+		Ports:       []string{"80:80"},
+	}
+}
+
+func TestServiceHashStable(t *testing.T) {
+	// Computed before the healthcheck, update_config and scale keys existed:
+	// a service not using them must keep its hash, or every existing
+	// container would be recreated.
+	assert.Equal(t, "640458506d208d0e58085e9c56a54582f0ccd249", GetServiceHash("web", hashBaselineConfig()))
+
+	scaled := hashBaselineConfig()
+	scaled.Scale = 3
+	scaled.UpdateConfig = &UpdateConfig{Parallelism: 2}
+	assert.Equal(t, "640458506d208d0e58085e9c56a54582f0ccd249", GetServiceHash("web", scaled))
+}
+
+func TestServiceHashHealthcheck(t *testing.T) {
+	withHealthcheck := hashBaselineConfig()
+	withHealthcheck.Healthcheck = &HealthCheck{Test: yaml.Stringorslice{"true"}}
+	assert.NotEqual(t, GetServiceHash("web", hashBaselineConfig()), GetServiceHash("web", withHealthcheck))
+}
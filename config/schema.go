@@ -179,7 +179,28 @@ var schemaV2 = `{
         "cpu_shares": {"type": ["number", "string"]},
         "cpu_quota": {"type": ["number", "string"]},
         "cpuset": {"type": "string"},
-        "depends_on": {"$ref": "#/definitions/list_of_strings"},
+        "depends_on": {
+          "oneOf": [
+            {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
+            {
+              "type": "object",
+              "patternProperties": {
+                "^[a-zA-Z0-9._-]+$": {
+                  "type": "object",
+                  "properties": {
+                    "condition": {
+                      "type": "string",
+                      "enum": ["service_started", "service_healthy", "service_completed_successfully"]
+                    }
+                  },
+                  "required": ["condition"],
+                  "additionalProperties": false
+                }
+              },
+              "additionalProperties": false
+            }
+          ]
+        },
         "dns": {"$ref": "#/definitions/string_or_list"},
         "dns_search": {"$ref": "#/definitions/string_or_list"},
         "domainname": {"type": "string"},
@@ -218,6 +239,7 @@ var schemaV2 = `{
         },
 
         "external_links": {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
+        "healthcheck": {"$ref": "#/definitions/healthcheck"},
         "hostname": {"type": "string"},
         "image": {"type": "string"},
         "labels": {"$ref": "#/definitions/list_or_dict"},
@@ -262,10 +284,21 @@ var schemaV2 = `{
         },
 
         "restart": {"type": "string"},
+        "scale": {"type": "integer", "minimum": 0},
         "stdin_open": {"type": "boolean"},
         "stop_signal": {"type": "string"},
         "security_groups": {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
         "tty": {"type": "boolean"},
+        "update_config": {
+          "type": "object",
+          "properties": {
+            "parallelism": {"type": "number"},
+            "delay": {"type": "string", "format": "duration"},
+            "order": {"type": "string", "enum": ["stop-first", "start-first"]},
+            "failure_action": {"type": "string", "enum": ["pause", "continue"]}
+          },
+          "additionalProperties": false
+        },
         "user": {"type": "string"},
         "volumes": {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
         "working_dir": {"type": "string"},
@@ -298,6 +331,24 @@ var schemaV2 = `{
       "additionalProperties": false
     },
 
+    "healthcheck": {
+      "id": "#/definitions/healthcheck",
+      "type": "object",
+      "properties": {
+        "test": {
+          "oneOf": [
+            {"type": "string"},
+            {"type": "array", "items": {"type": "string"}}
+          ]
+        },
+        "interval": {"type": "string", "format": "duration"},
+        "timeout": {"type": "string", "format": "duration"},
+        "retries": {"type": "number"},
+        "start_period": {"type": "string", "format": "duration"}
+      },
+      "additionalProperties": false
+    },
+
     "string_or_list": {
       "oneOf": [
         {"type": "string"},
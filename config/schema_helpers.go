@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/docker/go-connections/nat"
 	"github.com/xeipuuv/gojsonschema"
@@ -17,6 +18,7 @@ var (
 type (
 	environmentFormatChecker struct{}
 	portsFormatChecker       struct{}
+	durationFormatChecker    struct{}
 )
 
 func (checker environmentFormatChecker) IsFormat(input interface{}) bool {
@@ -31,6 +33,15 @@ func (checker portsFormatChecker) IsFormat(input string) bool {
 	return err == nil
 }
 
+func (checker durationFormatChecker) IsFormat(input interface{}) bool {
+	str, ok := input.(string)
+	if !ok {
+		return true
+	}
+	_, err := time.ParseDuration(str)
+	return err == nil
+}
+
 func setupSchemaLoaders(version string) error {
 	if schema != nil {
 		return nil
@@ -49,6 +60,7 @@ func setupSchemaLoaders(version string) error {
 	schema = schemaRaw.(map[string]interface{})
 
 	gojsonschema.FormatCheckers.Add("environment", environmentFormatChecker{})
+	gojsonschema.FormatCheckers.Add("duration", durationFormatChecker{})
 	//gojsonschema.FormatCheckers.Add("ports", portsFormatChecker{})
 	//gojsonschema.FormatCheckers.Add("expose", portsFormatChecker{})
 	schemaLoader = gojsonschema.NewGoLoader(schemaRaw)
@@ -88,6 +88,26 @@ type Log struct {
 	Options map[string]string `yaml:"options,omitempty"`
 }
 
+// HealthCheck holds v2 healthcheck information
+type HealthCheck struct {
+	Test     yaml.Stringorslice `yaml:"test,omitempty" json:"test,omitempty"`
+	Interval string             `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Timeout  string             `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Retries  int                `yaml:"retries,omitempty" json:"retries,omitempty"`
+	// StartPeriod is validated but ignored, with a warning, as the engine
+	// API does not support it.
+	StartPeriod string `yaml:"start_period,omitempty" json:"start_period,omitempty"`
+}
+
+// UpdateConfig holds v2 update strategy information, used when the containers
+// of a service are recreated
+type UpdateConfig struct {
+	Parallelism   int    `yaml:"parallelism,omitempty" json:"parallelism,omitempty"`
+	Delay         string `yaml:"delay,omitempty" json:"delay,omitempty"`
+	Order         string `yaml:"order,omitempty" json:"order,omitempty"`
+	FailureAction string `yaml:"failure_action,omitempty" json:"failure_action,omitempty"`
+}
+
 // ServiceConfig holds version 2 of libcompose service configuration
 type ServiceConfig struct {
 	/*
@@ -126,20 +146,23 @@ type ServiceConfig struct {
 	Command       yaml.Command         `yaml:"command,flow,omitempty" json:"command,omitempty"`
 	ContainerName string               `yaml:"container_name,omitempty" json:"container_name,omitempty"`
 	DomainName    string               `yaml:"domainname,omitempty" json:"domainname,omitempty"`
-	DependsOn     []string             `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
+	DependsOn     yaml.DependsOn       `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
 	Entrypoint    yaml.Command         `yaml:"entrypoint,flow,omitempty" json:"entrypoint,omitempty"`
 	EnvFile       yaml.Stringorslice   `yaml:"env_file,omitempty" json:"env_file,omitempty"`
 	Environment   yaml.MaporEqualSlice `yaml:"environment,omitempty" json:"environment,omitempty"`
 	Extends       yaml.MaporEqualSlice `yaml:"extends,omitempty" json:"extends,omitempty"`
 	ExternalLinks []string             `yaml:"external_links,omitempty" json:"external_links"`
 	Image         string               `yaml:"image,omitempty" json:"image,omitempty"`
+	Healthcheck   *HealthCheck         `yaml:"healthcheck,omitempty" json:"healthcheck,omitempty"`
 	Hostname      string               `yaml:"hostname,omitempty" json:"hostname,omitempty"`
 	Labels        yaml.SliceorMap      `yaml:"labels,omitempty" json:"labels,omitempty"`
 	Links         yaml.MaporColonSlice `yaml:"links,omitempty" json:"links,omitempty"`
 	Volumes       []string             `yaml:"volumes,omitempty" json:"volumes,omitempty"`
 	Restart       string               `yaml:"restart,omitempty" json:"restart,omitempty"`
+	Scale         int                  `yaml:"scale,omitempty" json:"scale,omitempty"`
 	StdinOpen     bool                 `yaml:"stdin_open,omitempty" json:"stdin_open,omitempty"`
 	Tty           bool                 `yaml:"tty,omitempty" json:"tty,omitempty"`
+	UpdateConfig  *UpdateConfig        `yaml:"update_config,omitempty" json:"update_config,omitempty"`
 	WorkingDir    string               `yaml:"working_dir,omitempty" json:"working_dir,omitempty"`
 
 	Size           string   `yaml:"size,omitempty" json:"size,omitempty"`
@@ -150,23 +173,23 @@ type ServiceConfig struct {
 
 // VolumeConfig holds v2 volume configuration
 type VolumeConfig struct {
-	Driver     string            `yaml:"driver,omitempty"`
-	DriverOpts map[string]string `yaml:"driver_opts,omitempty"`
-	External   bool              `yaml:"external,omitempty"`
+	Driver     string            `yaml:"driver,omitempty" json:"driver,omitempty"`
+	DriverOpts map[string]string `yaml:"driver_opts,omitempty" json:"driver_opts,omitempty"`
+	External   bool              `yaml:"external,omitempty" json:"external,omitempty"`
 }
 
 // Ipam holds v2 network IPAM information
 type Ipam struct {
-	Driver string   `yaml:"driver,omitempty"`
-	Config []string `yaml:"config,omitempty"`
+	Driver string   `yaml:"driver,omitempty" json:"driver,omitempty"`
+	Config []string `yaml:"config,omitempty" json:"config,omitempty"`
 }
 
 // NetworkConfig holds v2 network configuration
 type NetworkConfig struct {
-	Driver     string            `yaml:"driver,omitempty"`
-	DriverOpts map[string]string `yaml:"driver_opts,omitempty"`
-	External   bool              `yaml:"external,omitempty"`
-	Ipam       Ipam              `yaml:"ipam,omitempty"`
+	Driver     string            `yaml:"driver,omitempty" json:"driver,omitempty"`
+	DriverOpts map[string]string `yaml:"driver_opts,omitempty" json:"driver_opts,omitempty"`
+	External   bool              `yaml:"external,omitempty" json:"external,omitempty"`
+	Ipam       Ipam              `yaml:"ipam,omitempty" json:"ipam,omitempty"`
 }
 
 // Config holds libcompose top level configuration
@@ -177,6 +200,15 @@ type Config struct {
 	Networks map[string]*NetworkConfig `yaml:"networks,omitempty"`
 }
 
+// ResolvedConfig holds a v2 configuration once parsed, with the services
+// merged, extended and interpolated.
+type ResolvedConfig struct {
+	Version  string                    `yaml:"version" json:"version"`
+	Services map[string]*ServiceConfig `yaml:"services,omitempty" json:"services,omitempty"`
+	Volumes  map[string]*VolumeConfig  `yaml:"volumes,omitempty" json:"volumes,omitempty"`
+	Networks map[string]*NetworkConfig `yaml:"networks,omitempty" json:"networks,omitempty"`
+}
+
 // NewServiceConfigs initializes a new Configs struct
 func NewServiceConfigs() *ServiceConfigs {
 	return &ServiceConfigs{
@@ -2,6 +2,7 @@ package config
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -228,6 +229,18 @@ func TestValidConfigOneOfStringOrList(t *testing.T) {
 	}
 }
 
+func TestHealthcheckSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(schemaV2), &schema); err != nil {
+		t.Fatal(err)
+	}
+	healthcheck := schema["definitions"].(map[string]interface{})["healthcheck"].(map[string]interface{})
+	properties := healthcheck["properties"].(map[string]interface{})
+	for _, key := range []string{"test", "interval", "timeout", "retries", "start_period"} {
+		assert.Contains(t, properties, key)
+	}
+}
+
 func TestInvalidServiceProperty(t *testing.T) {
 	testInvalidSchema(t, RawServiceMap{
 		"web": map[string]interface{}{
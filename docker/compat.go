@@ -0,0 +1,186 @@
+package docker
+
+import (
+	"github.com/docker/engine-api/types"
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project"
+	"github.com/hyperhq/libcompose/project/options"
+	"golang.org/x/net/context"
+)
+
+// The operations below predate the context.Context argument, they run the
+// corresponding Context operation with context.Background().
+
+// Create implements Service.Create.
+func (s *Service) Create(options options.Create) error {
+	return s.CreateContext(context.Background(), options)
+}
+
+// Build implements Service.Build.
+func (s *Service) Build(buildOptions options.Build) error {
+	return s.BuildContext(context.Background(), buildOptions)
+}
+
+// Up implements Service.Up.
+func (s *Service) Up(options options.Up) error {
+	return s.UpContext(context.Background(), options)
+}
+
+// Info implements Service.Info.
+func (s *Service) Info(qFlag bool) (project.InfoSet, error) {
+	return s.InfoContext(context.Background(), qFlag)
+}
+
+// Start implements Service.Start.
+func (s *Service) Start() error {
+	return s.StartContext(context.Background())
+}
+
+// Stop implements Service.Stop.
+func (s *Service) Stop(timeout int) error {
+	return s.StopContext(context.Background(), timeout)
+}
+
+// Restart implements Service.Restart.
+func (s *Service) Restart(timeout int) error {
+	return s.RestartContext(context.Background(), timeout)
+}
+
+// Kill implements Service.Kill.
+func (s *Service) Kill(signal string) error {
+	return s.KillContext(context.Background(), signal)
+}
+
+// Delete implements Service.Delete.
+func (s *Service) Delete(options options.Delete) error {
+	return s.DeleteContext(context.Background(), options)
+}
+
+// Log implements Service.Log.
+func (s *Service) Log(follow bool) error {
+	return s.LogContext(context.Background(), options.Log{Follow: follow})
+}
+
+// Scale implements Service.Scale.
+func (s *Service) Scale(scale int, timeout int) error {
+	return s.ScaleContext(context.Background(), scale, timeout)
+}
+
+// Pull implements Service.Pull.
+func (s *Service) Pull() error {
+	return s.PullContext(context.Background())
+}
+
+// Pause implements Service.Pause.
+func (s *Service) Pause() error {
+	return s.PauseContext(context.Background())
+}
+
+// Unpause implements Service.Unpause.
+func (s *Service) Unpause() error {
+	return s.UnpauseContext(context.Background())
+}
+
+// RemoveImage implements Service.RemoveImage.
+func (s *Service) RemoveImage(imageType options.ImageType) error {
+	return s.RemoveImageContext(context.Background(), imageType)
+}
+
+// Containers implements Service.Containers.
+func (s *Service) Containers() ([]project.Container, error) {
+	return s.ContainersContext(context.Background())
+}
+
+// Info returns info about the container, like name, command, state or ports.
+func (c *Container) Info(qFlag bool) (project.Info, error) {
+	return c.InfoContext(context.Background(), qFlag)
+}
+
+// Recreate will not refresh the container by means of relaxation and enjoyment,
+// just delete it and create a new one with the current configuration
+func (c *Container) Recreate(imageName string) (*types.ContainerJSON, error) {
+	return c.RecreateContext(context.Background(), imageName)
+}
+
+// Create creates the container based on the specified image name and send an event
+// to notify the container has been created. If the container already exists, does
+// nothing.
+func (c *Container) Create(imageName string) (*types.ContainerJSON, error) {
+	return c.CreateContext(context.Background(), imageName)
+}
+
+// CreateWithOverride create container and override parts of the config to
+// allow special situations to override the config generated from the compose
+// file
+func (c *Container) CreateWithOverride(imageName string, configOverride *config.ServiceConfig) (*types.ContainerJSON, error) {
+	return c.CreateWithOverrideContext(context.Background(), imageName, configOverride)
+}
+
+// Stop stops the container.
+func (c *Container) Stop(timeout int) error {
+	return c.StopContext(context.Background(), timeout)
+}
+
+// Pause pauses the container. If the containers are already paused, don't fail.
+func (c *Container) Pause() error {
+	return c.PauseContext(context.Background())
+}
+
+// Unpause unpauses the container. If the containers are not paused, don't fail.
+func (c *Container) Unpause() error {
+	return c.UnpauseContext(context.Background())
+}
+
+// Kill kill the container.
+func (c *Container) Kill(signal string) error {
+	return c.KillContext(context.Background(), signal)
+}
+
+// Delete removes the container if existing. If the container is running, it tries
+// to stop it first.
+func (c *Container) Delete(removeVolume bool) error {
+	return c.DeleteContext(context.Background(), removeVolume)
+}
+
+// IsRunning returns the running state of the container.
+func (c *Container) IsRunning() (bool, error) {
+	return c.IsRunningContext(context.Background())
+}
+
+// Up creates and start the container based on the image name and send an event
+// to notify the container has been created. If the container exists but is stopped
+// it tries to start it.
+func (c *Container) Up(imageName string) error {
+	return c.UpContext(context.Background(), imageName)
+}
+
+// Start the specified container with the specified host config
+func (c *Container) Start(container *types.ContainerJSON) error {
+	return c.StartContext(context.Background(), container)
+}
+
+// OutOfSync checks if the container is out of sync with the service definition.
+// It looks if the the service hash container label is the same as the computed one.
+func (c *Container) OutOfSync(imageName string) (bool, error) {
+	return c.OutOfSyncContext(context.Background(), imageName)
+}
+
+// ID returns the container Id.
+func (c *Container) ID() (string, error) {
+	return c.IDContext(context.Background())
+}
+
+// Restart restarts the container if existing, does nothing otherwise.
+func (c *Container) Restart(timeout int) error {
+	return c.RestartContext(context.Background(), timeout)
+}
+
+// Log forwards container logs to the project configured logger.
+func (c *Container) Log(follow bool) error {
+	return c.LogContext(context.Background(), options.Log{Follow: follow})
+}
+
+// Port returns the host port the specified port is mapped on.
+func (c *Container) Port(port string) (string, error) {
+	return c.PortContext(context.Background(), port)
+}
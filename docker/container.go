@@ -5,7 +5,9 @@ import (
 	"io"
 	"math"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/docker/engine-api/client"
@@ -21,6 +23,7 @@ import (
 	"github.com/hyperhq/libcompose/logger"
 	"github.com/hyperhq/libcompose/project"
 	"github.com/hyperhq/libcompose/project/events"
+	"github.com/hyperhq/libcompose/project/options"
 	util "github.com/hyperhq/libcompose/utils"
 	"golang.org/x/net/context"
 )
@@ -65,18 +68,18 @@ func NewOneOffContainer(client client.APIClient, name string, containerNumber in
 	return c
 }
 
-func (c *Container) findExisting() (*types.ContainerJSON, error) {
-	return GetContainer(c.client, c.name)
+func (c *Container) findExisting(ctx context.Context) (*types.ContainerJSON, error) {
+	return GetContainer(ctx, c.client, c.name)
 }
 
-// Info returns info about the container, like name, command, state or ports.
-func (c *Container) Info(qFlag bool) (project.Info, error) {
-	container, err := c.findExisting()
+// InfoContext returns info about the container, like name, command, state or ports.
+func (c *Container) InfoContext(ctx context.Context, qFlag bool) (project.Info, error) {
+	container, err := c.findExisting(ctx)
 	if err != nil || container == nil {
 		return nil, err
 	}
 
-	infos, err := GetContainersByFilter(c.client, map[string][]string{
+	infos, err := GetContainersByFilter(ctx, c.client, map[string][]string{
 		"name": {container.Name},
 	})
 	if err != nil || len(infos) == 0 {
@@ -97,6 +100,47 @@ func (c *Container) Info(qFlag bool) (project.Info, error) {
 	return result, nil
 }
 
+// Status returns the status of the container, or nil if it does not exist.
+func (c *Container) Status(ctx context.Context) (*project.ContainerStatus, error) {
+	container, err := c.findExisting(ctx)
+	if err != nil || container == nil {
+		return nil, err
+	}
+
+	status := &project.ContainerStatus{
+		Service: c.serviceName,
+		Number:  c.containerNumber,
+		ID:      container.ID,
+		Name:    c.name,
+		Image:   container.Config.Image,
+		Ports:   []string{},
+	}
+	status.Created, _ = time.Parse(time.RFC3339Nano, container.Created)
+
+	if container.State != nil {
+		status.State = container.State.Status
+		status.ExitCode = container.State.ExitCode
+		status.Started, _ = time.Parse(time.RFC3339Nano, container.State.StartedAt)
+		if container.State.Health != nil {
+			status.Health = container.State.Health.Status
+		}
+	}
+
+	if container.NetworkSettings != nil {
+		for port, bindings := range container.NetworkSettings.Ports {
+			if len(bindings) == 0 {
+				status.Ports = append(status.Ports, string(port))
+			}
+			for _, binding := range bindings {
+				status.Ports = append(status.Ports, fmt.Sprintf("%s:%s->%s", binding.HostIP, binding.HostPort, port))
+			}
+		}
+		sort.Strings(status.Ports)
+	}
+
+	return status, nil
+}
+
 func portString(ports []types.Port) string {
 	result := []string{}
 
@@ -125,10 +169,21 @@ func name(names []string) string {
 	return current[1:]
 }
 
-// Recreate will not refresh the container by means of relaxation and enjoyment,
+// RecreateContext will not refresh the container by means of relaxation and enjoyment,
 // just delete it and create a new one with the current configuration
-func (c *Container) Recreate(imageName string) (*types.ContainerJSON, error) {
-	container, err := c.findExisting()
+func (c *Container) RecreateContext(ctx context.Context, imageName string) (*types.ContainerJSON, error) {
+	return c.recreate(ctx, imageName, nil)
+}
+
+// recreate renames the existing container, creates its replacement and removes
+// the existing one. If beforeRemove is set, it is called with the replacement
+// before the existing container is removed. If it fails, the replacement is
+// removed and the existing container gets its name back.
+//
+// In a transactional operation, the existing container is stopped instead of
+// being removed, and is only removed when the transaction is committed.
+func (c *Container) recreate(ctx context.Context, imageName string, beforeRemove func(*types.ContainerJSON) error) (*types.ContainerJSON, error) {
+	container, err := c.findExisting(ctx)
 	if err != nil || container == nil {
 		return nil, err
 	}
@@ -141,18 +196,59 @@ func (c *Container) Recreate(imageName string) (*types.ContainerJSON, error) {
 	name := container.Name[1:]
 	newName := fmt.Sprintf("%s-%s", name, container.ID[:12])
 	logrus.Debugf("Renaming %s => %s", name, newName)
-	if err := c.client.ContainerRename(context.Background(), container.ID, newName); err != nil {
+	if err := c.client.ContainerRename(ctx, container.ID, newName); err != nil {
 		logrus.Errorf("Failed to rename old container %s", c.name)
 		return nil, err
 	}
 
-	newContainer, err := c.createContainer(imageName, container.ID, nil)
+	tx := project.TransactionFromContext(ctx)
+	var step *recreateStep
+	if tx != nil {
+		step = &recreateStep{
+			container: c,
+			oldID:     container.ID,
+			name:      name,
+		}
+		tx.Add(step)
+		if beforeRemove == nil && container.State != nil && container.State.Running {
+			if err := c.client.ContainerStop(ctx, container.ID, 10); err != nil {
+				return nil, err
+			}
+			step.stopped = true
+		}
+	}
+
+	newContainer, err := c.createContainer(ctx, imageName, container.ID, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 	logrus.Debugf("Created replacement container %s", newContainer.ID)
 
-	if _, err := c.client.ContainerRemove(context.Background(), container.ID, types.ContainerRemoveOptions{
+	if step != nil {
+		step.newID = newContainer.ID
+		if beforeRemove != nil {
+			if err := beforeRemove(newContainer); err != nil {
+				return nil, err
+			}
+		}
+		return newContainer, nil
+	}
+
+	if beforeRemove != nil {
+		if err := beforeRemove(newContainer); err != nil {
+			logrus.Errorf("Replacement container of %s failed, keeping the old one: %v", c.name, err)
+			if _, removeErr := c.client.ContainerRemove(ctx, newContainer.ID, types.ContainerRemoveOptions{
+				Force: true,
+			}); removeErr != nil {
+				logrus.Errorf("Failed to remove replacement container %s: %v", newContainer.ID, removeErr)
+			} else if renameErr := c.client.ContainerRename(ctx, container.ID, name); renameErr != nil {
+				logrus.Errorf("Failed to rename old container %s back: %v", newName, renameErr)
+			}
+			return nil, err
+		}
+	}
+
+	if _, err := c.client.ContainerRemove(ctx, container.ID, types.ContainerRemoveOptions{
 		Force:         true,
 		RemoveVolumes: false,
 	}); err != nil {
@@ -164,24 +260,24 @@ func (c *Container) Recreate(imageName string) (*types.ContainerJSON, error) {
 	return newContainer, nil
 }
 
-// Create creates the container based on the specified image name and send an event
+// CreateContext creates the container based on the specified image name and send an event
 // to notify the container has been created. If the container already exists, does
 // nothing.
-func (c *Container) Create(imageName string) (*types.ContainerJSON, error) {
-	return c.CreateWithOverride(imageName, nil)
+func (c *Container) CreateContext(ctx context.Context, imageName string) (*types.ContainerJSON, error) {
+	return c.CreateWithOverrideContext(ctx, imageName, nil)
 }
 
-// CreateWithOverride create container and override parts of the config to
+// CreateWithOverrideContext create container and override parts of the config to
 // allow special situations to override the config generated from the compose
 // file
-func (c *Container) CreateWithOverride(imageName string, configOverride *config.ServiceConfig) (*types.ContainerJSON, error) {
-	container, err := c.findExisting()
+func (c *Container) CreateWithOverrideContext(ctx context.Context, imageName string, configOverride *config.ServiceConfig) (*types.ContainerJSON, error) {
+	container, err := c.findExisting(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	if container == nil {
-		container, err = c.createContainer(imageName, "", configOverride)
+		container, err = c.createContainer(ctx, imageName, "", c.service.overrideConfig(configOverride), nil)
 		if err != nil {
 			return nil, err
 		}
@@ -193,55 +289,55 @@ func (c *Container) CreateWithOverride(imageName string, configOverride *config.
 	return container, err
 }
 
-// Stop stops the container.
-func (c *Container) Stop(timeout int) error {
-	return c.withContainer(func(container *types.ContainerJSON) error {
-		return c.client.ContainerStop(context.Background(), container.ID, timeout)
+// StopContext stops the container.
+func (c *Container) StopContext(ctx context.Context, timeout int) error {
+	return c.withContainer(ctx, func(container *types.ContainerJSON) error {
+		return c.client.ContainerStop(ctx, container.ID, timeout)
 	})
 }
 
-// Pause pauses the container. If the containers are already paused, don't fail.
-func (c *Container) Pause() error {
-	return c.withContainer(func(container *types.ContainerJSON) error {
+// PauseContext pauses the container. If the containers are already paused, don't fail.
+func (c *Container) PauseContext(ctx context.Context) error {
+	return c.withContainer(ctx, func(container *types.ContainerJSON) error {
 		if !container.State.Paused {
-			return c.client.ContainerPause(context.Background(), container.ID)
+			return c.client.ContainerPause(ctx, container.ID)
 		}
 		return nil
 	})
 }
 
-// Unpause unpauses the container. If the containers are not paused, don't fail.
-func (c *Container) Unpause() error {
-	return c.withContainer(func(container *types.ContainerJSON) error {
+// UnpauseContext unpauses the container. If the containers are not paused, don't fail.
+func (c *Container) UnpauseContext(ctx context.Context) error {
+	return c.withContainer(ctx, func(container *types.ContainerJSON) error {
 		if container.State.Paused {
-			return c.client.ContainerUnpause(context.Background(), container.ID)
+			return c.client.ContainerUnpause(ctx, container.ID)
 		}
 		return nil
 	})
 }
 
-// Kill kill the container.
-func (c *Container) Kill(signal string) error {
-	return c.withContainer(func(container *types.ContainerJSON) error {
-		return c.client.ContainerKill(context.Background(), container.ID, signal)
+// KillContext kill the container.
+func (c *Container) KillContext(ctx context.Context, signal string) error {
+	return c.withContainer(ctx, func(container *types.ContainerJSON) error {
+		return c.client.ContainerKill(ctx, container.ID, signal)
 	})
 }
 
-// Delete removes the container if existing. If the container is running, it tries
+// DeleteContext removes the container if existing. If the container is running, it tries
 // to stop it first.
-func (c *Container) Delete(removeVolume bool) error {
-	container, err := c.findExisting()
+func (c *Container) DeleteContext(ctx context.Context, removeVolume bool) error {
+	container, err := c.findExisting(ctx)
 	if err != nil || container == nil {
 		return err
 	}
 
-	info, err := c.client.ContainerInspect(context.Background(), container.ID)
+	info, err := c.client.ContainerInspect(ctx, container.ID)
 	if err != nil {
 		return err
 	}
 
 	if !info.State.Running {
-		_, err := c.client.ContainerRemove(context.Background(), container.ID, types.ContainerRemoveOptions{
+		_, err := c.client.ContainerRemove(ctx, container.ID, types.ContainerRemoveOptions{
 			Force:         true,
 			RemoveVolumes: removeVolume,
 		})
@@ -251,14 +347,14 @@ func (c *Container) Delete(removeVolume bool) error {
 	return nil
 }
 
-// IsRunning returns the running state of the container.
-func (c *Container) IsRunning() (bool, error) {
-	container, err := c.findExisting()
+// IsRunningContext returns the running state of the container.
+func (c *Container) IsRunningContext(ctx context.Context) (bool, error) {
+	container, err := c.findExisting(ctx)
 	if err != nil || container == nil {
 		return false, err
 	}
 
-	info, err := c.client.ContainerInspect(context.Background(), container.ID)
+	info, err := c.client.ContainerInspect(ctx, container.ID)
 	if err != nil {
 		return false, err
 	}
@@ -266,51 +362,88 @@ func (c *Container) IsRunning() (bool, error) {
 	return info.State.Running, nil
 }
 
-// Run creates, start and attach to the container based on the image name,
-// the specified configuration.
-// It will always create a new container.
-func (c *Container) Run(ctx context.Context, imageName string, configOverride *config.ServiceConfig) (int, error) {
-	var (
-		errCh       chan error
-		out, stderr io.Writer
-		in          io.ReadCloser
-	)
+// Health returns the health status of the container, or an empty string if
+// the container does not exist or has no healthcheck.
+func (c *Container) Health(ctx context.Context) (string, error) {
+	container, err := c.findExisting(ctx)
+	if err != nil || container == nil {
+		return "", err
+	}
 
-	container, err := c.createContainer(imageName, "", configOverride)
+	if container.State == nil || container.State.Health == nil {
+		return "", nil
+	}
+
+	return container.State.Health.Status, nil
+}
+
+// Wait blocks until the container stops and returns its exit code.
+func (c *Container) Wait(ctx context.Context) (int, error) {
+	container, err := c.findExisting(ctx)
 	if err != nil {
 		return -1, err
 	}
+	if container == nil {
+		return -1, fmt.Errorf("Container %s does not exist", c.name)
+	}
+
+	return c.client.ContainerWait(ctx, container.ID)
+}
 
-	if configOverride.StdinOpen {
-		in = os.Stdin
+// Run creates, start and attach to the container based on the image name,
+// the specified configuration, used in place of the service one, and the
+// specified options.
+// It will always create a new container. In detached mode, it returns as soon
+// as the container is started.
+func (c *Container) Run(ctx context.Context, imageName string, configOverride *config.ServiceConfig, runOptions options.Run) (int, error) {
+	var errCh chan error
+
+	if err := validateDetachKeys(runOptions.DetachKeys); err != nil {
+		return -1, err
+	}
+
+	container, err := c.createContainer(ctx, imageName, "", configOverride, &runOptions)
+	if err != nil {
+		return -1, err
 	}
-	if configOverride.Tty {
-		out = os.Stdout
+
+	if runOptions.Detach {
+		if err := c.StartContext(ctx, container); err != nil {
+			return -1, err
+		}
+		return 0, nil
 	}
-	if configOverride.Tty {
-		stderr = os.Stderr
+
+	in, out, stderr := runStreams(runOptions)
+	if !configOverride.StdinOpen {
+		in = nil
 	}
 
 	options := types.ContainerAttachOptions{
-		Stream: true,
-		Stdin:  configOverride.StdinOpen,
-		Stdout: configOverride.Tty,
-		Stderr: configOverride.Tty,
+		Stream:     true,
+		Stdin:      in != nil,
+		Stdout:     true,
+		Stderr:     true,
+		DetachKeys: runOptions.DetachKeys,
 	}
 
 	resp, err := c.client.ContainerAttach(ctx, container.ID, options)
 	if err != nil {
 		return -1, err
 	}
+	defer resp.Close()
 
-	// set raw terminal
-	inFd, _ := term.GetFdInfo(in)
-	state, err := term.SetRawTerminal(inFd)
-	if err != nil {
-		return -1, err
+	if configOverride.Tty && in != nil {
+		// set raw terminal, if the input is one
+		if inFd, isTerminal := term.GetFdInfo(in); isTerminal {
+			state, err := term.SetRawTerminal(inFd)
+			if err != nil {
+				return -1, err
+			}
+			// restore raw terminal
+			defer term.RestoreTerminal(inFd, state)
+		}
 	}
-	// restore raw terminal
-	defer term.RestoreTerminal(inFd, state)
 	// holdHijackedConnection (in goroutine)
 	errCh = promise.Go(func() error {
 		return holdHijackedConnection(configOverride.Tty, in, out, stderr, resp)
@@ -320,6 +453,15 @@ func (c *Container) Run(ctx context.Context, imageName string, configOverride *c
 		return -1, err
 	}
 
+	if runOptions.ProxySignals {
+		defer c.forwardSignals(ctx, container.ID)()
+	}
+	if outFd, isTerminal := term.GetFdInfo(out); configOverride.Tty && isTerminal {
+		defer monitorTtySize(ctx, outFd, func(ctx context.Context, options types.ResizeOptions) error {
+			return c.client.ContainerResize(ctx, container.ID, options)
+		})()
+	}
+
 	if err := <-errCh; err != nil {
 		logrus.Debugf("Error hijack: %s", err)
 		return -1, err
@@ -362,6 +504,9 @@ func (c *Container) Run(ctx context.Context, imageName string, configOverride *c
 			if err != nil {
 				return -1, err
 			}
+			if exitedContainer.State.Running {
+				logrus.Infof("Detached from %s", c.name)
+			}
 			status = exitedContainer.State.ExitCode
 		}
 	}
@@ -369,7 +514,149 @@ func (c *Container) Run(ctx context.Context, imageName string, configOverride *c
 	return status, nil
 }
 
-func holdHijackedConnection(tty bool, inputStream io.ReadCloser, outputStream, errorStream io.Writer, resp types.HijackedResponse) error {
+// runStreams returns the streams of the specified run options, defaulting to
+// the standard ones.
+func runStreams(runOptions options.Run) (io.Reader, io.Writer, io.Writer) {
+	var (
+		in          io.Reader = os.Stdin
+		out, stderr io.Writer = os.Stdout, os.Stderr
+	)
+	if runOptions.Stdin != nil {
+		in = runOptions.Stdin
+	}
+	if runOptions.Stdout != nil {
+		out = runOptions.Stdout
+	}
+	if runOptions.Stderr != nil {
+		stderr = runOptions.Stderr
+	}
+	return in, out, stderr
+}
+
+// Exec executes the specified command in the container, which has to be
+// running, and returns its exit code. In detached mode, it returns as soon as
+// the command is started.
+func (c *Container) Exec(ctx context.Context, commandParts []string, options options.Exec) (int, error) {
+	container, err := c.findExisting(ctx)
+	if err != nil {
+		return -1, err
+	}
+	if container == nil {
+		return -1, fmt.Errorf("Container %s does not exist", c.name)
+	}
+	if !container.State.Running {
+		return -1, fmt.Errorf("Container %s is not running", c.name)
+	}
+
+	execConfig := types.ExecConfig{
+		User:         options.User,
+		Tty:          options.Tty,
+		AttachStdin:  options.Interactive && !options.Detach,
+		AttachStdout: !options.Detach,
+		AttachStderr: !options.Detach,
+		Detach:       options.Detach,
+		DetachKeys:   options.DetachKeys,
+		Cmd:          commandParts,
+	}
+	if err := validateDetachKeys(options.DetachKeys); err != nil {
+		return -1, err
+	}
+
+	exec, err := c.client.ContainerExecCreate(ctx, container.ID, execConfig)
+	if err != nil {
+		return -1, err
+	}
+
+	if options.Detach {
+		if err := c.client.ContainerExecStart(ctx, exec.ID, types.ExecStartCheck{
+			Detach: true,
+			Tty:    options.Tty,
+		}); err != nil {
+			return -1, err
+		}
+		return 0, nil
+	}
+
+	resp, err := c.client.ContainerExecAttach(ctx, exec.ID, execConfig)
+	if err != nil {
+		return -1, err
+	}
+	defer resp.Close()
+
+	in, out, stderr := execStreams(options)
+	if !execConfig.AttachStdin {
+		in = nil
+	} else if inFd, isTerminal := term.GetFdInfo(in); options.Tty && isTerminal {
+		// set raw terminal, if the input is one
+		state, err := term.SetRawTerminal(inFd)
+		if err != nil {
+			return -1, err
+		}
+		// restore raw terminal
+		defer term.RestoreTerminal(inFd, state)
+	}
+	if outFd, isTerminal := term.GetFdInfo(out); options.Tty && isTerminal {
+		defer monitorTtySize(ctx, outFd, func(ctx context.Context, resizeOptions types.ResizeOptions) error {
+			return c.client.ContainerExecResize(ctx, exec.ID, resizeOptions)
+		})()
+	}
+	if options.ProxySignals {
+		// The engine has no API to signal an exec, the container gets them.
+		defer c.forwardSignals(ctx, container.ID)()
+	}
+
+	if err := holdHijackedConnection(options.Tty, in, out, stderr, resp); err != nil {
+		logrus.Debugf("Error hijack: %s", err)
+		return -1, err
+	}
+
+	inspect, err := c.client.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return -1, err
+	}
+
+	return inspect.ExitCode, nil
+}
+
+// execStreams returns the streams of the specified exec options, defaulting
+// to the standard ones.
+func execStreams(execOptions options.Exec) (io.Reader, io.Writer, io.Writer) {
+	return runStreams(options.Run{
+		Stdin:  execOptions.Stdin,
+		Stdout: execOptions.Stdout,
+		Stderr: execOptions.Stderr,
+	})
+}
+
+// CopyTo extracts the specified tar stream to the specified path in the
+// container.
+func (c *Container) CopyTo(ctx context.Context, path string, content io.Reader) error {
+	container, err := c.findExisting(ctx)
+	if err != nil {
+		return err
+	}
+	if container == nil {
+		return fmt.Errorf("Container %s does not exist", c.name)
+	}
+
+	return c.client.CopyToContainer(ctx, container.ID, path, content, types.CopyToContainerOptions{})
+}
+
+// CopyFrom returns a tar stream of the specified path in the container.
+func (c *Container) CopyFrom(ctx context.Context, path string) (io.ReadCloser, error) {
+	container, err := c.findExisting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if container == nil {
+		return nil, fmt.Errorf("Container %s does not exist", c.name)
+	}
+
+	content, _, err := c.client.CopyFromContainer(ctx, container.ID, path)
+	return content, err
+}
+
+func holdHijackedConnection(tty bool, inputStream io.Reader, outputStream, errorStream io.Writer, resp types.HijackedResponse) error {
 	var err error
 	receiveStdout := make(chan error, 1)
 	if outputStream != nil || errorStream != nil {
@@ -416,28 +703,35 @@ func holdHijackedConnection(tty bool, inputStream io.ReadCloser, outputStream, e
 	return nil
 }
 
-// Up creates and start the container based on the image name and send an event
+// UpContext creates and start the container based on the image name and send an event
 // to notify the container has been created. If the container exists but is stopped
 // it tries to start it.
-func (c *Container) Up(imageName string) error {
+func (c *Container) UpContext(ctx context.Context, imageName string) error {
 	var err error
 
-	container, err := c.Create(imageName)
+	container, err := c.CreateContext(ctx, imageName)
 	if err != nil {
 		return err
 	}
 
 	if !container.State.Running {
-		c.Start(container)
+		if err := c.StartContext(ctx, container); err != nil {
+			return err
+		}
+		if tx := project.TransactionFromContext(ctx); tx != nil {
+			tx.Add(project.NewTransactionStep(nil, func(ctx context.Context) error {
+				return c.client.ContainerStop(ctx, container.ID, 10)
+			}))
+		}
 	}
 
 	return nil
 }
 
-// Start the specified container with the specified host config
-func (c *Container) Start(container *types.ContainerJSON) error {
+// StartContext the specified container with the specified host config
+func (c *Container) StartContext(ctx context.Context, container *types.ContainerJSON) error {
 	logrus.WithFields(logrus.Fields{"container.ID": container.ID, "c.name": c.name}).Debug("Starting container")
-	if err := c.client.ContainerStart(context.Background(), container.ID, ""); err != nil {
+	if err := c.client.ContainerStart(ctx, container.ID, ""); err != nil {
 		logrus.WithFields(logrus.Fields{"container.ID": container.ID, "c.name": c.name}).Debug("Failed to start container")
 		return err
 	}
@@ -447,35 +741,45 @@ func (c *Container) Start(container *types.ContainerJSON) error {
 	return nil
 }
 
-// OutOfSync checks if the container is out of sync with the service definition.
+// OutOfSyncContext checks if the container is out of sync with the service definition.
 // It looks if the the service hash container label is the same as the computed one.
-func (c *Container) OutOfSync(imageName string) (bool, error) {
-	container, err := c.findExisting()
+func (c *Container) OutOfSyncContext(ctx context.Context, imageName string) (bool, error) {
+	reason, err := c.outOfSyncReason(ctx, imageName)
+	return reason != "", err
+}
+
+// outOfSyncReason returns why the container is out of sync with the service
+// definition, or an empty string if it is not.
+func (c *Container) outOfSyncReason(ctx context.Context, imageName string) (string, error) {
+	container, err := c.findExisting(ctx)
 	if err != nil || container == nil {
-		return false, err
+		return "", err
 	}
 
 	if container.Config.Image != imageName {
 		logrus.Debugf("Images for %s do not match %s!=%s", c.name, container.Config.Image, imageName)
-		return true, nil
+		return fmt.Sprintf("image changed from %s to %s", container.Config.Image, imageName), nil
 	}
 
 	if container.Config.Labels[labels.HASH.Str()] != c.getHash() {
 		logrus.Debugf("Hashes for %s do not match %s!=%s", c.name, container.Config.Labels[labels.HASH.Str()], c.getHash())
-		return true, nil
+		return "service configuration changed", nil
 	}
 
-	image, _, err := c.client.ImageInspectWithRaw(context.Background(), container.Config.Image, false)
+	image, _, err := c.client.ImageInspectWithRaw(ctx, container.Config.Image, false)
 	if err != nil {
 		if client.IsErrImageNotFound(err) {
 			logrus.Debugf("Image %s do not exist, do not know if it's out of sync", container.Config.Image)
-			return false, nil
+			return "", nil
 		}
-		return false, err
+		return "", err
 	}
 
 	logrus.Debugf("Checking existing image name vs id: %s == %s", image.ID, container.Image)
-	return image.ID != container.Image, err
+	if image.ID != container.Image {
+		return fmt.Sprintf("image %s was updated", container.Config.Image), nil
+	}
+	return "", nil
 }
 
 func (c *Container) getHash() string {
@@ -492,20 +796,32 @@ func volumeBinds(volumes map[string]struct{}, container *types.ContainerJSON) []
 	return result
 }
 
-func (c *Container) createContainer(imageName, oldContainer string, configOverride *config.ServiceConfig) (*types.ContainerJSON, error) {
-	serviceConfig := c.service.serviceConfig
-	if configOverride != nil {
-		serviceConfig.Command = configOverride.Command
-		serviceConfig.Tty = configOverride.Tty
-		serviceConfig.StdinOpen = configOverride.StdinOpen
+// createContainer creates the container from the specified configuration, or
+// from the service one if nil. The run options, if any, set what the service
+// configuration does not hold, like the user and the published ports.
+func (c *Container) createContainer(ctx context.Context, imageName, oldContainer string, serviceConfig *config.ServiceConfig, runOptions *options.Run) (*types.ContainerJSON, error) {
+	if serviceConfig == nil {
+		serviceConfig = c.service.serviceConfig
 	}
-	configWrapper, err := ConvertToAPI(c.service)
+	configWrapper, err := convertConfigToAPI(serviceConfig, c.service.context)
 	if err != nil {
 		return nil, err
 	}
 
 	configWrapper.Config.Image = imageName
 
+	if runOptions != nil {
+		configWrapper.Config.User = runOptions.User
+		if len(runOptions.Ports) != 0 {
+			exposedPorts, portBindings, err := nat.ParsePortSpecs(runOptions.Ports)
+			if err != nil {
+				return nil, err
+			}
+			configWrapper.Config.ExposedPorts = exposedPorts
+			configWrapper.HostConfig.PortBindings = portBindings
+		}
+	}
+
 	if configWrapper.Config.Labels == nil {
 		configWrapper.Config.Labels = map[string]string{}
 	}
@@ -527,13 +843,13 @@ func (c *Container) createContainer(imageName, oldContainer string, configOverri
 	}
 	configWrapper.Config.Labels["sh_hyper_instancetype"] = size
 
-	err = c.populateAdditionalHostConfig(configWrapper.HostConfig)
+	err = c.populateAdditionalHostConfig(ctx, configWrapper.HostConfig)
 	if err != nil {
 		return nil, err
 	}
 
 	if oldContainer != "" {
-		info, err := c.client.ContainerInspect(context.Background(), oldContainer)
+		info, err := c.client.ContainerInspect(ctx, oldContainer)
 		if err != nil {
 			return nil, err
 		}
@@ -542,19 +858,24 @@ func (c *Container) createContainer(imageName, oldContainer string, configOverri
 
 	logrus.Debugf("Creating container %s %#v", c.name, configWrapper)
 
-	container, err := c.client.ContainerCreate(context.Background(), configWrapper.Config, configWrapper.HostConfig, configWrapper.NetworkingConfig, c.name)
+	container, err := c.client.ContainerCreate(ctx, configWrapper.Config, configWrapper.HostConfig, configWrapper.NetworkingConfig, c.name)
 	if err != nil {
 		logrus.Debugf("Failed to create container %s: %v", c.name, err)
 		return nil, err
 	}
 
-	return GetContainer(c.client, container.ID)
+	return GetContainer(ctx, c.client, container.ID)
 }
 
-func (c *Container) populateAdditionalHostConfig(hostConfig *container.HostConfig) error {
+func (c *Container) populateAdditionalHostConfig(ctx context.Context, hostConfig *container.HostConfig) error {
 	links := map[string]string{}
 
 	for _, link := range c.service.DependentServices() {
+		if link.Type == project.RelTypeDependsOn {
+			// depends_on only orders the services, it does not link them
+			continue
+		}
+
 		if !c.service.context.Project.ServiceConfigs.Has(link.Target) {
 			continue
 		}
@@ -564,7 +885,7 @@ func (c *Container) populateAdditionalHostConfig(hostConfig *container.HostConfi
 			return err
 		}
 
-		containers, err := service.Containers()
+		containers, err := service.ContainersContext(ctx)
 		if err != nil {
 			return err
 		}
@@ -635,9 +956,9 @@ func (c *Container) addNetNs(config *container.HostConfig, service project.Servi
 	return config, nil
 }
 
-// ID returns the container Id.
-func (c *Container) ID() (string, error) {
-	container, err := c.findExisting()
+// IDContext returns the container Id.
+func (c *Container) IDContext(ctx context.Context) (string, error) {
+	container, err := c.findExisting(ctx)
 	if container == nil {
 		return "", err
 	}
@@ -649,56 +970,121 @@ func (c *Container) Name() string {
 	return c.name
 }
 
-// Restart restarts the container if existing, does nothing otherwise.
-func (c *Container) Restart(timeout int) error {
-	container, err := c.findExisting()
+// RestartContext restarts the container if existing, does nothing otherwise.
+func (c *Container) RestartContext(ctx context.Context, timeout int) error {
+	container, err := c.findExisting(ctx)
 	if err != nil || container == nil {
 		return err
 	}
 
-	return c.client.ContainerRestart(context.Background(), container.ID, timeout)
+	return c.client.ContainerRestart(ctx, container.ID, timeout)
 }
 
-// Log forwards container logs to the project configured logger.
-func (c *Container) Log(follow bool) error {
-	container, err := c.findExisting()
+// LogContext forwards container logs to the project configured logger.
+func (c *Container) LogContext(ctx context.Context, options options.Log) error {
+	container, err := c.findExisting(ctx)
 	if container == nil || err != nil {
 		return err
 	}
 
-	info, err := c.client.ContainerInspect(context.Background(), container.ID)
+	info, err := c.client.ContainerInspect(ctx, container.ID)
 	if err != nil {
 		return err
 	}
 
+	now := time.Now()
+	logsOptions := types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     options.Follow,
+		Tail:       options.Tail,
+		Timestamps: options.Timestamps,
+	}
+	if logsOptions.Tail == "" {
+		logsOptions.Tail = "all"
+	}
+	if options.Since != "" {
+		since, err := parseLogTime(options.Since, now)
+		if err != nil {
+			return err
+		}
+		logsOptions.Since = fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond())
+	}
+	logsCtx := ctx
+	var until time.Time
+	if options.Until != "" {
+		if until, err = parseLogTime(options.Until, now); err != nil {
+			return err
+		}
+		// The engine does not support until, the logs are filtered on
+		// their timestamp
+		logsOptions.Timestamps = true
+
+		// Followed logs stop once until is reached, even if no later line
+		// is ever written.
+		if options.Follow {
+			if !until.After(now) {
+				logsOptions.Follow = false
+			} else {
+				var cancel context.CancelFunc
+				logsCtx, cancel = context.WithCancel(ctx)
+				defer cancel()
+				timer := time.AfterFunc(until.Sub(now), cancel)
+				defer timer.Stop()
+			}
+		}
+	}
+
 	// FIXME(vdemeester) update container struct to do less API calls
 	name := fmt.Sprintf("%s-%d", c.service.name, c.containerNumber)
 	l := c.loggerFactory.Create(name)
-
-	options := types.ContainerLogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
-		Follow:     follow,
-		Tail:       "all",
+	if closer, ok := l.(io.Closer); ok {
+		defer closer.Close()
 	}
-	responseBody, err := c.client.ContainerLogs(context.Background(), container.ID, options)
+
+	responseBody, err := c.client.ContainerLogs(logsCtx, container.ID, logsOptions)
 	if err != nil {
 		return err
 	}
 	defer responseBody.Close()
 
+	var out, stderr io.Writer = &logger.Wrapper{Logger: l}, &logger.Wrapper{Logger: l, Err: true}
+	var untilWriters []*untilWriter
+	if !until.IsZero() {
+		untilWriters = []*untilWriter{
+			newUntilWriter(out, until, options.Timestamps),
+			newUntilWriter(stderr, until, options.Timestamps),
+		}
+		out, stderr = untilWriters[0], untilWriters[1]
+	}
+
 	if info.Config.Tty {
-		_, err = io.Copy(&logger.Wrapper{Logger: l}, responseBody)
+		_, err = io.Copy(out, responseBody)
 	} else {
-		_, err = stdcopy.StdCopy(&logger.Wrapper{Logger: l}, &logger.Wrapper{Logger: l, Err: true}, responseBody)
+		_, err = stdcopy.StdCopy(out, stderr, responseBody)
 	}
 	logrus.WithFields(logrus.Fields{"Logger": l, "err": err}).Debug("c.client.Logs() returned error")
 
+	if err != nil && logsCtx.Err() != nil && ctx.Err() == nil {
+		// The followed logs were cut when until was reached.
+		err = nil
+	}
+	if err == nil {
+		for _, w := range untilWriters {
+			if err := w.Flush(); err != nil && err != errUntilReached {
+				return err
+			}
+		}
+	}
+
+	if err == errUntilReached {
+		return nil
+	}
 	return err
 }
 
-func (c *Container) withContainer(action func(*types.ContainerJSON) error) error {
-	container, err := c.findExisting()
+func (c *Container) withContainer(ctx context.Context, action func(*types.ContainerJSON) error) error {
+	container, err := c.findExisting(ctx)
 	if err != nil {
 		return err
 	}
@@ -710,9 +1096,9 @@ func (c *Container) withContainer(action func(*types.ContainerJSON) error) error
 	return nil
 }
 
-// Port returns the host port the specified port is mapped on.
-func (c *Container) Port(port string) (string, error) {
-	container, err := c.findExisting()
+// PortContext returns the host port the specified port is mapped on.
+func (c *Container) PortContext(ctx context.Context, port string) (string, error) {
+	container, err := c.findExisting(ctx)
 	if err != nil {
 		return "", err
 	}
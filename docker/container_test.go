@@ -0,0 +1,407 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	engineclient "github.com/docker/engine-api/client"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/container"
+	"github.com/docker/engine-api/types/network"
+	"github.com/hyperhq/hypercli/pkg/stdcopy"
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/labels"
+	"github.com/hyperhq/libcompose/project"
+	"github.com/hyperhq/libcompose/project/options"
+	"github.com/hyperhq/libcompose/test"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestResourceUsage(t *testing.T) {
+	stats := &types.StatsJSON{
+		Stats: types.Stats{
+			PreCPUStats: types.CPUStats{
+				CPUUsage:    types.CPUUsage{TotalUsage: 100},
+				SystemUsage: 1000,
+			},
+			CPUStats: types.CPUStats{
+				CPUUsage:    types.CPUUsage{TotalUsage: 200, PercpuUsage: []uint64{100, 100}},
+				SystemUsage: 2000,
+			},
+			MemoryStats: types.MemoryStats{Usage: 64, Limit: 128},
+			BlkioStats: types.BlkioStats{
+				IoServiceBytesRecursive: []types.BlkioStatEntry{
+					{Op: "Read", Value: 10},
+					{Op: "Write", Value: 20},
+					{Op: "Read", Value: 5},
+					{Op: "Total", Value: 35},
+				},
+			},
+		},
+		Networks: map[string]types.NetworkStats{
+			"eth0": {RxBytes: 1, TxBytes: 2},
+			"eth1": {RxBytes: 3, TxBytes: 4},
+		},
+	}
+
+	assert.Equal(t, project.ResourceUsage{
+		CPUPercent:  20,
+		MemoryUsage: 64,
+		MemoryLimit: 128,
+		NetworkRx:   4,
+		NetworkTx:   6,
+		BlockRead:   15,
+		BlockWrite:  20,
+	}, resourceUsage(stats))
+}
+
+// FakeClient fakes the engine for the containers it created or marked as
+// running, named by their id without its "id-" prefix, and records the calls
+// made to it, each as the operation followed by the container name.
+type FakeClient struct {
+	test.NopClient
+	sync.Mutex
+	running map[string]bool
+	// exitCode is the exit code of the containers and of the execs.
+	exitCode int
+	tty      bool
+	// output is the output of the attached containers and execs.
+	output   []byte
+	logs     string
+	copied   map[string]string
+	attached types.ContainerAttachOptions
+	created  *container.Config
+	exec     types.ExecConfig
+	calls    []string
+}
+
+func (client *FakeClient) record(operation, id string) string {
+	client.Lock()
+	defer client.Unlock()
+	name := strings.TrimPrefix(id, "id-")
+	client.calls = append(client.calls, operation+" "+name)
+	return name
+}
+
+// called returns the containers the specified operation was called on, in
+// the order of the calls.
+func (client *FakeClient) called(operation string) []string {
+	client.Lock()
+	defer client.Unlock()
+	names := []string{}
+	for _, call := range client.calls {
+		if strings.HasPrefix(call, operation+" ") {
+			names = append(names, strings.TrimPrefix(call, operation+" "))
+		}
+	}
+	return names
+}
+
+func (client *FakeClient) setRunning(name string, running bool) {
+	client.Lock()
+	defer client.Unlock()
+	if client.running == nil {
+		client.running = map[string]bool{}
+	}
+	client.running[name] = running
+}
+
+// Create implements project.ClientFactory, the client is shared by every
+// service.
+func (client *FakeClient) Create(service project.Service) engineclient.APIClient {
+	return client
+}
+
+// ContainerList lists the containers marked as running or stopped, named
+// project_service_number.
+func (client *FakeClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	client.Lock()
+	defer client.Unlock()
+	containers := []types.Container{}
+	for name := range client.running {
+		parts := strings.Split(name, "_")
+		containers = append(containers, types.Container{
+			ID:    "id-" + name,
+			Names: []string{"/" + name},
+			Labels: map[string]string{
+				labels.PROJECT.Str(): parts[0],
+				labels.SERVICE.Str(): parts[1],
+				labels.NUMBER.Str():  parts[len(parts)-1],
+				labels.ONEOFF.Str():  "False",
+			},
+		})
+	}
+	return containers, nil
+}
+
+func (client *FakeClient) ContainerInspect(ctx context.Context, id string) (types.ContainerJSON, error) {
+	client.Lock()
+	defer client.Unlock()
+	name := strings.TrimPrefix(id, "id-")
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:    "id-" + name,
+			State: &types.ContainerState{Running: client.running[name]},
+		},
+		Config: &container.Config{Tty: client.tty},
+	}, nil
+}
+
+func (client *FakeClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (types.ContainerCreateResponse, error) {
+	client.record("create", containerName)
+	client.Lock()
+	defer client.Unlock()
+	client.created = config
+	return types.ContainerCreateResponse{ID: "id-" + containerName}, nil
+}
+
+func (client *FakeClient) ContainerAttach(ctx context.Context, container string, options types.ContainerAttachOptions) (types.HijackedResponse, error) {
+	client.record("attach", container)
+	client.Lock()
+	defer client.Unlock()
+	client.attached = options
+	conn, _ := net.Pipe()
+	return types.HijackedResponse{
+		Conn:   conn,
+		Reader: bufio.NewReader(bytes.NewReader(client.output)),
+	}, nil
+}
+
+func (client *FakeClient) ContainerStart(ctx context.Context, container, checkpointID string) error {
+	client.setRunning(client.record("start", container), true)
+	return nil
+}
+
+func (client *FakeClient) ContainerStop(ctx context.Context, container string, timeout int) error {
+	client.setRunning(client.record("stop", container), false)
+	return nil
+}
+
+func (client *FakeClient) ContainerKill(ctx context.Context, container, signal string) error {
+	client.record("kill", container)
+	return nil
+}
+
+func (client *FakeClient) ContainerRemove(ctx context.Context, container string, options types.ContainerRemoveOptions) ([]string, error) {
+	client.record("remove", container)
+	return nil, nil
+}
+
+func (client *FakeClient) ContainerWait(ctx context.Context, container string) (int, error) {
+	client.record("wait", container)
+	return client.exitCode, nil
+}
+
+func (client *FakeClient) ContainerLogs(ctx context.Context, container string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	client.record("logs", container)
+	return &followedLogs{ctx: ctx, logs: strings.NewReader(client.logs)}, nil
+}
+
+func (client *FakeClient) ContainerExecCreate(ctx context.Context, container string, config types.ExecConfig) (types.ContainerExecCreateResponse, error) {
+	client.record("exec", container)
+	client.Lock()
+	defer client.Unlock()
+	client.exec = config
+	return types.ContainerExecCreateResponse{ID: "exec-" + container}, nil
+}
+
+func (client *FakeClient) ContainerExecAttach(ctx context.Context, execID string, config types.ExecConfig) (types.HijackedResponse, error) {
+	conn, _ := net.Pipe()
+	return types.HijackedResponse{
+		Conn:   conn,
+		Reader: bufio.NewReader(bytes.NewReader(client.output)),
+	}, nil
+}
+
+func (client *FakeClient) ContainerExecInspect(ctx context.Context, execID string) (types.ContainerExecInspect, error) {
+	return types.ContainerExecInspect{ExitCode: client.exitCode}, nil
+}
+
+func (client *FakeClient) CopyToContainer(ctx context.Context, container, path string, content io.Reader, options types.CopyToContainerOptions) error {
+	data, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	client.Lock()
+	defer client.Unlock()
+	if client.copied == nil {
+		client.copied = map[string]string{}
+	}
+	client.copied[container+":"+path] = string(data)
+	return nil
+}
+
+func (client *FakeClient) CopyFromContainer(ctx context.Context, container, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+	client.Lock()
+	defer client.Unlock()
+	return ioutil.NopCloser(strings.NewReader(client.copied[container+":"+srcPath])), types.ContainerPathStat{}, nil
+}
+
+// followedLogs returns logs, then blocks as followed logs do until the
+// context is done.
+type followedLogs struct {
+	ctx  context.Context
+	logs *strings.Reader
+}
+
+func (r *followedLogs) Read(p []byte) (int, error) {
+	if r.logs.Len() > 0 {
+		return r.logs.Read(p)
+	}
+	<-r.ctx.Done()
+	return 0, r.ctx.Err()
+}
+
+func (r *followedLogs) Close() error {
+	return nil
+}
+
+func TestCopy(t *testing.T) {
+	client := &FakeClient{}
+	container := &Container{name: "db_1", client: client}
+
+	if err := container.CopyTo(context.Background(), "/fixtures", strings.NewReader("tar")); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{"id-db_1:/fixtures": "tar"}, client.copied)
+
+	content, err := container.CopyFrom(context.Background(), "/fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer content.Close()
+	data, err := ioutil.ReadAll(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "tar", string(data))
+}
+
+func TestExecStreams(t *testing.T) {
+	output := &bytes.Buffer{}
+	stdcopy.NewStdWriter(output, stdcopy.Stdout).Write([]byte("total 0\n"))
+	client := &FakeClient{output: output.Bytes(), exitCode: 2, running: map[string]bool{"web_1": true}}
+	service := &Service{
+		name: "web",
+		context: &Context{
+			Context: project.Context{
+				Project: &project.Project{ServiceConfigs: config.NewServiceConfigs()},
+			},
+		},
+	}
+	c := NewContainer(client, "web_1", 1, service)
+
+	execOptions := options.Exec{
+		Stdout: &bytes.Buffer{},
+		Stderr: &bytes.Buffer{},
+	}
+	code, err := c.Exec(context.Background(), []string{"ls", "-l"}, execOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, code)
+	assert.Equal(t, []string{"ls", "-l"}, client.exec.Cmd)
+	assert.False(t, client.exec.AttachStdin)
+	assert.Equal(t, "total 0\n", execOptions.Stdout.(*bytes.Buffer).String())
+}
+
+// signalingWriter sends a signal to the test process on its first write, and
+// waits until the fake client was asked to kill a container with it.
+type signalingWriter struct {
+	client *FakeClient
+	sent   bool
+}
+
+func (w *signalingWriter) Write(p []byte) (int, error) {
+	if !w.sent {
+		w.sent = true
+		syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+		for i := 0; i < 100 && len(w.client.called("kill")) == 0; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	return len(p), nil
+}
+
+func TestExecProxySignals(t *testing.T) {
+	output := &bytes.Buffer{}
+	stdcopy.NewStdWriter(output, stdcopy.Stdout).Write([]byte("sleeping\n"))
+	client := &FakeClient{output: output.Bytes(), running: map[string]bool{"web_1": true}}
+	service := &Service{
+		name: "web",
+		context: &Context{
+			Context: project.Context{
+				Project: &project.Project{ServiceConfigs: config.NewServiceConfigs()},
+			},
+		},
+	}
+	c := NewContainer(client, "web_1", 1, service)
+
+	if _, err := c.Exec(context.Background(), []string{"sleep", "60"}, options.Exec{
+		Stdout:       &signalingWriter{client: client},
+		Stderr:       &bytes.Buffer{},
+		ProxySignals: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"web_1"}, client.called("kill"))
+}
+
+func TestRunWithoutTty(t *testing.T) {
+	output := &bytes.Buffer{}
+	stdcopy.NewStdWriter(output, stdcopy.Stdout).Write([]byte("migrated\n"))
+	client := &FakeClient{output: output.Bytes(), exitCode: 3}
+
+	service := &Service{
+		name:          "db",
+		serviceConfig: &config.ServiceConfig{Image: "postgres"},
+		context: &Context{
+			Context: project.Context{
+				Project: &project.Project{ServiceConfigs: config.NewServiceConfigs()},
+			},
+		},
+	}
+	runOptions := options.Run{
+		DisableTty: true,
+		Stdout:     &bytes.Buffer{},
+		Stderr:     &bytes.Buffer{},
+	}
+	c := NewOneOffContainer(client, "db_run_1", 1, service)
+
+	code, err := c.Run(context.Background(), "postgres", service.runConfig([]string{"migrate"}, runOptions), runOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, code)
+	assert.False(t, client.created.Tty)
+	assert.False(t, client.created.OpenStdin)
+	assert.Equal(t, types.ContainerAttachOptions{Stream: true, Stdout: true, Stderr: true}, client.attached)
+	assert.Equal(t, "migrated\n", runOptions.Stdout.(*bytes.Buffer).String())
+}
+
+func TestProxySignals(t *testing.T) {
+	sigc := make(chan os.Signal, 4)
+	sigc <- syscall.SIGTERM
+	sigc <- syscall.SIGWINCH
+	sigc <- syscall.SIGCHLD
+	sigc <- syscall.SIGINT
+	close(sigc)
+
+	killed := []string{}
+	proxySignals(sigc, func(sig string) error {
+		killed = append(killed, sig)
+		return nil
+	})
+	assert.Equal(t, []string{fmt.Sprint(int(syscall.SIGTERM)), fmt.Sprint(int(syscall.SIGINT))}, killed)
+}
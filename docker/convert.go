@@ -1,7 +1,9 @@
 package docker
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/docker/engine-api/types/container"
 	"github.com/docker/engine-api/types/network"
@@ -10,6 +12,7 @@ import (
 	"github.com/hyperhq/libcompose/project"
 	"github.com/hyperhq/libcompose/utils"
 	"github.com/hyperhq/hypercli/runconfig/opts"
+	"github.com/sirupsen/logrus"
 )
 
 // ConfigWrapper wraps Config, HostConfig and NetworkingConfig for a container.
@@ -40,7 +43,11 @@ func isVolume(s string) bool {
 
 // ConvertToAPI converts a service configuration to a docker API container configuration.
 func ConvertToAPI(s *Service) (*ConfigWrapper, error) {
-	config, hostConfig, err := Convert(s.serviceConfig, s.context.Context)
+	return convertConfigToAPI(s.serviceConfig, s.context)
+}
+
+func convertConfigToAPI(serviceConfig *config.ServiceConfig, ctx *Context) (*ConfigWrapper, error) {
+	config, hostConfig, err := Convert(serviceConfig, ctx.Context)
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +87,49 @@ func restartPolicy(c *config.ServiceConfig) (*container.RestartPolicy, error) {
 	return &container.RestartPolicy{Name: restart.Name, MaximumRetryCount: restart.MaximumRetryCount}, nil
 }
 
+func healthcheck(c *config.ServiceConfig) (*container.HealthConfig, error) {
+	if c.Healthcheck == nil {
+		return nil, nil
+	}
+
+	test := utils.CopySlice(c.Healthcheck.Test)
+	if len(test) == 1 && test[0] != "NONE" {
+		test = []string{"CMD-SHELL", test[0]}
+	}
+	healthcheck := &container.HealthConfig{
+		Test:    test,
+		Retries: c.Healthcheck.Retries,
+	}
+
+	durations := []struct {
+		name  string
+		value string
+		field *time.Duration
+	}{
+		{"interval", c.Healthcheck.Interval, &healthcheck.Interval},
+		{"timeout", c.Healthcheck.Timeout, &healthcheck.Timeout},
+	}
+	for _, duration := range durations {
+		if duration.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(duration.value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid healthcheck %s %q: %v", duration.name, duration.value, err)
+		}
+		*duration.field = d
+	}
+
+	if c.Healthcheck.StartPeriod != "" {
+		if _, err := time.ParseDuration(c.Healthcheck.StartPeriod); err != nil {
+			return nil, fmt.Errorf("Invalid healthcheck start_period %q: %v", c.Healthcheck.StartPeriod, err)
+		}
+		logrus.Warnf("The healthcheck start_period %s is ignored, the engine API does not support it", c.Healthcheck.StartPeriod)
+	}
+
+	return healthcheck, nil
+}
+
 /*
 func ports(c *config.ServiceConfig) (map[nat.Port]struct{}, nat.PortMap, error) {
 	ports, binding, err := nat.ParsePortSpecs(c.Ports)
@@ -120,6 +170,11 @@ func Convert(c *config.ServiceConfig, ctx project.Context) (*container.Config, *
 		return nil, nil, err
 	}
 
+	healthcheck, err := healthcheck(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	/*
 		exposedPorts, portBindings, err := ports(c)
 		if err != nil {
@@ -154,6 +209,7 @@ func Convert(c *config.ServiceConfig, ctx project.Context) (*container.Config, *
 		WorkingDir: c.WorkingDir,
 		Volumes:    volumes(c, ctx),
 		//MacAddress: c.MacAddress,
+		Healthcheck: healthcheck,
 	}
 
 	/*
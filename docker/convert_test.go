@@ -3,6 +3,7 @@ package docker
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
 	shlex "github.com/flynn/go-shlex"
 	"github.com/hyperhq/libcompose/config"
@@ -56,3 +57,33 @@ func TestParseLabels(t *testing.T) {
 	assert.Equal(t, yaml.Command{bashCmd}, sc.Entrypoint)
 	assert.Equal(t, []string{"less"}, []string(cfg.Entrypoint))
 }
+
+func TestParseHealthcheck(t *testing.T) {
+	ctx := &Context{}
+	ctx.ComposeFiles = []string{"foo/docker-compose.yml"}
+	ctx.ResourceLookup = &lookup.FileConfigLookup{}
+	cfg, _, err := Convert(&config.ServiceConfig{
+		Healthcheck: &config.HealthCheck{
+			Test:        yaml.Stringorslice{"curl -f http://localhost"},
+			Interval:    "30s",
+			Timeout:     "5s",
+			Retries:     3,
+			StartPeriod: "1m",
+		},
+	}, ctx.Context)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"CMD-SHELL", "curl -f http://localhost"}, cfg.Healthcheck.Test)
+	assert.Equal(t, 30*time.Second, cfg.Healthcheck.Interval)
+	assert.Equal(t, 5*time.Second, cfg.Healthcheck.Timeout)
+	assert.Equal(t, 3, cfg.Healthcheck.Retries)
+
+	_, _, err = Convert(&config.ServiceConfig{
+		Healthcheck: &config.HealthCheck{Interval: "often"},
+	}, ctx.Context)
+	assert.NotNil(t, err)
+
+	_, _, err = Convert(&config.ServiceConfig{
+		Healthcheck: &config.HealthCheck{StartPeriod: "later"},
+	}, ctx.Context)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,160 @@
+package docker
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/docker/engine-api/client"
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/labels"
+	"github.com/hyperhq/libcompose/project"
+)
+
+// ProjectSummary holds what the endpoint knows about a compose project, from
+// the labels of its containers.
+type ProjectSummary struct {
+	Name string
+	// Services holds the name of the services, sorted.
+	Services []string
+	// Containers holds the number of containers by state (running, exited…).
+	Containers map[string]int
+	// Versions holds the compose versions the containers were created with,
+	// sorted.
+	Versions []string
+	// Hashes holds the config hashes in use, sorted, by service.
+	Hashes map[string][]string
+}
+
+// ListProjects lists every compose project having containers on the
+// endpoint, sorted by name.
+func ListProjects(ctx context.Context, client client.APIClient) ([]ProjectSummary, error) {
+	containers, err := GetContainersByFilter(ctx, client, map[string][]string{
+		"label": {labels.PROJECT.Str()},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := map[string]*ProjectSummary{}
+	for _, container := range containers {
+		name := container.Labels[labels.PROJECT.Str()]
+		summary, ok := summaries[name]
+		if !ok {
+			summary = &ProjectSummary{
+				Name:       name,
+				Containers: map[string]int{},
+				Hashes:     map[string][]string{},
+			}
+			summaries[name] = summary
+		}
+
+		service := container.Labels[labels.SERVICE.Str()]
+		summary.Services = appendUnique(summary.Services, service)
+		summary.Containers[container.State]++
+		if version := container.Labels[labels.VERSION.Str()]; version != "" {
+			summary.Versions = appendUnique(summary.Versions, version)
+		}
+		if hash := container.Labels[labels.HASH.Str()]; hash != "" {
+			summary.Hashes[service] = appendUnique(summary.Hashes[service], hash)
+		}
+	}
+
+	names := make([]string, 0, len(summaries))
+	for name := range summaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]ProjectSummary, 0, len(names))
+	for _, name := range names {
+		summary := summaries[name]
+		sort.Strings(summary.Services)
+		sort.Strings(summary.Versions)
+		for _, hashes := range summary.Hashes {
+			sort.Strings(hashes)
+		}
+		result = append(result, *summary)
+	}
+
+	return result, nil
+}
+
+// NewProjectFromEndpoint rebuilds a minimal project with the specified name
+// from its containers on the endpoint, without its compose files. Its services
+// only know their image, which is enough to stop, kill, remove them or bring
+// the project down, but not to create containers.
+func NewProjectFromEndpoint(ctx context.Context, context *Context, name string) (project.APIProject, error) {
+	if err := initContext(context); err != nil {
+		return nil, err
+	}
+
+	containers, err := GetContainersByFilter(ctx, context.ClientFactory.Create(nil), labels.PROJECT.Eq(name))
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("No such project: %s", name)
+	}
+
+	context.ProjectName = name
+	p := project.NewProject(context.ClientFactory, &context.Context)
+	p.Name = name
+	for _, container := range serviceContainers(containers) {
+		p.ServiceConfigs.Add(container.Labels[labels.SERVICE.Str()], &config.ServiceConfig{
+			Image: container.Image,
+		})
+	}
+
+	if err := context.open(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// serviceContainers returns a container for each service, the one with the
+// lowest number, sorted by service.
+func serviceContainers(containers []types.Container) []types.Container {
+	byService := map[string]types.Container{}
+	for _, container := range containers {
+		service := container.Labels[labels.SERVICE.Str()]
+		existing, ok := byService[service]
+		if !ok || containerNumber(container) < containerNumber(existing) {
+			byService[service] = container
+		}
+	}
+
+	services := make([]string, 0, len(byService))
+	for service := range byService {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	result := make([]types.Container, 0, len(services))
+	for _, service := range services {
+		result = append(result, byService[service])
+	}
+	return result
+}
+
+func containerNumber(container types.Container) int {
+	number, err := strconv.Atoi(container.Labels[labels.NUMBER.Str()])
+	if err != nil {
+		return math.MaxInt32
+	}
+	return number
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
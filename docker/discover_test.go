@@ -0,0 +1,109 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/engine-api/client"
+	"github.com/docker/engine-api/types"
+	"github.com/hyperhq/hypercli/cliconfig"
+	"github.com/hyperhq/libcompose/labels"
+	"github.com/hyperhq/libcompose/project"
+	"github.com/hyperhq/libcompose/test"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+type ListClient struct {
+	test.NopClient
+	containers []types.Container
+}
+
+func (client *ListClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	return client.containers, nil
+}
+
+type ListClientFactory struct {
+	client *ListClient
+}
+
+func (factory *ListClientFactory) Create(service project.Service) client.APIClient {
+	return factory.client
+}
+
+func composeContainer(projectName, service, number, state, hash, image string) types.Container {
+	return types.Container{
+		Image: image,
+		State: state,
+		Labels: map[string]string{
+			labels.PROJECT.Str(): projectName,
+			labels.SERVICE.Str(): service,
+			labels.NUMBER.Str():  number,
+			labels.HASH.Str():    hash,
+			labels.VERSION.Str(): ComposeVersion,
+		},
+	}
+}
+
+func TestListProjects(t *testing.T) {
+	client := &ListClient{
+		containers: []types.Container{
+			composeContainer("shop", "web", "1", "running", "aaa", "nginx"),
+			composeContainer("blog", "app", "1", "exited", "ccc", "wordpress"),
+			composeContainer("shop", "web", "2", "running", "bbb", "nginx"),
+			composeContainer("shop", "db", "1", "exited", "ddd", "postgres"),
+		},
+	}
+
+	projects, err := ListProjects(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []ProjectSummary{
+		{
+			Name:       "blog",
+			Services:   []string{"app"},
+			Containers: map[string]int{"exited": 1},
+			Versions:   []string{ComposeVersion},
+			Hashes:     map[string][]string{"app": {"ccc"}},
+		},
+		{
+			Name:       "shop",
+			Services:   []string{"db", "web"},
+			Containers: map[string]int{"running": 2, "exited": 1},
+			Versions:   []string{ComposeVersion},
+			Hashes:     map[string][]string{"db": {"ddd"}, "web": {"aaa", "bbb"}},
+		},
+	}, projects)
+}
+
+func TestNewProjectFromEndpoint(t *testing.T) {
+	client := &ListClient{
+		containers: []types.Container{
+			composeContainer("shop", "web", "10", "running", "aaa", "nginx:old"),
+			composeContainer("shop", "web", "9", "running", "aaa", "nginx"),
+			composeContainer("shop", "db", "1", "exited", "ddd", "postgres"),
+		},
+	}
+
+	p, err := NewProjectFromEndpoint(context.Background(), &Context{
+		ClientFactory: &ListClientFactory{client: client},
+		ConfigFile:    &cliconfig.ConfigFile{},
+	}, "shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serviceConfigs, _, _ := p.GetConfig()
+	assert.ElementsMatch(t, []string{"db", "web"}, serviceConfigs.Keys())
+	web, _ := serviceConfigs.Get("web")
+	assert.Equal(t, "nginx", web.Image)
+
+	client.containers = nil
+	if _, err := NewProjectFromEndpoint(context.Background(), &Context{
+		ClientFactory: &ListClientFactory{client: client},
+		ConfigFile:    &cliconfig.ConfigFile{},
+	}, "shop"); err == nil {
+		t.Fatal("expected an error for an unknown project")
+	}
+}
@@ -0,0 +1,111 @@
+package docker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// errUntilReached stops the copy of the logs once they reached their until
+// time.
+var errUntilReached = errors.New("until reached")
+
+// logTimeLayouts are the layouts of the absolute times accepted by since and
+// until.
+var logTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parseLogTime parses the since or until option of the logs: a duration
+// relative to now, a RFC 3339 date or a Unix timestamp.
+func parseLogTime(value string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+
+	for _, layout := range logTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+
+	return time.Time{}, fmt.Errorf("Invalid time %q, expected a duration, a RFC 3339 date or a Unix timestamp", value)
+}
+
+// untilWriter writes the lines of logs, prefixed by their timestamp, up to the
+// until time. It then fails with errUntilReached. The timestamps are removed
+// unless keepTimestamps is set. The last line, if it does not end with a
+// newline, is only written by Flush.
+type untilWriter struct {
+	out            io.Writer
+	until          time.Time
+	keepTimestamps bool
+	buffer         []byte
+	reached        bool
+}
+
+func newUntilWriter(out io.Writer, until time.Time, keepTimestamps bool) *untilWriter {
+	return &untilWriter{
+		out:            out,
+		until:          until,
+		keepTimestamps: keepTimestamps,
+	}
+}
+
+func (w *untilWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+	for {
+		i := bytes.IndexByte(w.buffer, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := w.buffer[:i+1]
+		w.buffer = w.buffer[i+1:]
+
+		if err := w.writeLine(line); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Flush writes the buffered line that did not end with a newline, once the
+// logs ended.
+func (w *untilWriter) Flush() error {
+	if len(w.buffer) == 0 {
+		return nil
+	}
+	line := w.buffer
+	w.buffer = nil
+	return w.writeLine(line)
+}
+
+func (w *untilWriter) writeLine(line []byte) error {
+	if w.reached {
+		return errUntilReached
+	}
+
+	if j := bytes.IndexByte(line, ' '); j > 0 {
+		if t, err := time.Parse(time.RFC3339Nano, string(line[:j])); err == nil {
+			if t.After(w.until) {
+				w.reached = true
+				return errUntilReached
+			}
+			if !w.keepTimestamps {
+				line = line[j+1:]
+			}
+		}
+	}
+
+	_, err := w.out.Write(line)
+	return err
+}
@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperhq/libcompose/logger"
+	"github.com/hyperhq/libcompose/project/options"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestParseLogTime(t *testing.T) {
+	now := time.Date(2016, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"10m", time.Date(2016, 6, 1, 11, 50, 0, 0, time.UTC)},
+		{"1h30m", time.Date(2016, 6, 1, 10, 30, 0, 0, time.UTC)},
+		{"2016-05-31T08:00:00Z", time.Date(2016, 5, 31, 8, 0, 0, 0, time.UTC)},
+		{"2016-05-31T08:00:00.5+02:00", time.Date(2016, 5, 31, 6, 0, 0, 500000000, time.UTC)},
+		{"1464681600", time.Date(2016, 5, 31, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		parsed, err := parseLogTime(c.value, now)
+		if err != nil {
+			t.Fatalf("%s: %v", c.value, err)
+		}
+		if !parsed.Equal(c.expected) {
+			t.Fatalf("%s: expected %v, got %v", c.value, c.expected, parsed)
+		}
+	}
+
+	if _, err := parseLogTime("yesterday", now); err == nil {
+		t.Fatal("expected an error for an invalid time")
+	}
+}
+
+func TestUntilWriter(t *testing.T) {
+	logs := strings.Join([]string{
+		"2016-06-01T11:58:00.000000000Z starting",
+		"2016-06-01T11:59:00.000000000Z ready",
+		"2016-06-01T12:01:00.000000000Z too late",
+		"",
+	}, "\n")
+	until := time.Date(2016, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	out := &bytes.Buffer{}
+	w := newUntilWriter(out, until, false)
+	// Written in chunks not aligned on lines
+	_, err := w.Write([]byte(logs[:20]))
+	assert.Nil(t, err)
+	_, err = w.Write([]byte(logs[20:]))
+	assert.Equal(t, errUntilReached, err)
+	assert.Equal(t, "starting\nready\n", out.String())
+
+	out.Reset()
+	w = newUntilWriter(out, until, true)
+	_, err = w.Write([]byte(logs))
+	assert.Equal(t, errUntilReached, err)
+	assert.Equal(t, "2016-06-01T11:58:00.000000000Z starting\n2016-06-01T11:59:00.000000000Z ready\n", out.String())
+
+	// The last line is written once the logs ended, even without a newline
+	out.Reset()
+	w = newUntilWriter(out, until, false)
+	_, err = w.Write([]byte(logs[:strings.Index(logs, "\n2016-06-01T12:01")]))
+	assert.Nil(t, err)
+	assert.Equal(t, "starting\n", out.String())
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, "starting\nready", out.String())
+}
+
+func TestLogFollowUntil(t *testing.T) {
+	now := time.Now()
+	client := &FakeClient{
+		tty:  true,
+		logs: now.Add(-time.Second).Format(time.RFC3339Nano) + " ready\n" + now.Format(time.RFC3339Nano) + " last",
+	}
+	out := &bytes.Buffer{}
+	c := &Container{
+		name:            "web_1",
+		containerNumber: 1,
+		client:          client,
+		service:         &Service{name: "web"},
+		loggerFactory:   logger.NewJSONLoggerFactory("project", out),
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- c.LogContext(context.Background(), options.Log{
+			Follow: true,
+			Until:  now.Add(100 * time.Millisecond).Format(time.RFC3339Nano),
+		})
+	}()
+
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("The followed logs did not stop at until")
+	}
+	assert.Contains(t, out.String(), `"ready"`)
+	assert.Contains(t, out.String(), `"last"`)
+}
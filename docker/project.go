@@ -16,6 +16,29 @@ const ComposeVersion = "1.5.0"
 
 // NewProject creates a Project with the specified context.
 func NewProject(context *Context) (project.APIProject, error) {
+	if err := initContext(context); err != nil {
+		return nil, err
+	}
+
+	// FIXME(vdemeester) Remove the context duplication ?
+	p := project.NewProject(context.ClientFactory, &context.Context)
+
+	err := p.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = context.open(); err != nil {
+		logrus.Errorf("Failed to open project %s: %v", p.Name, err)
+		return nil, err
+	}
+
+	return p, err
+}
+
+// initContext sets the default lookups and service factory of the specified
+// context, if not set.
+func initContext(context *Context) error {
 	if context.ResourceLookup == nil {
 		context.ResourceLookup = &lookup.FileConfigLookup{}
 	}
@@ -23,7 +46,7 @@ func NewProject(context *Context) (project.APIProject, error) {
 	if context.EnvironmentLookup == nil {
 		cwd, err := os.Getwd()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		context.EnvironmentLookup = &lookup.ComposableEnvLookup{
 			Lookups: []config.EnvironmentLookup{
@@ -46,21 +69,8 @@ func NewProject(context *Context) (project.APIProject, error) {
 	}
 
 	if context.ClientFactory == nil {
-		return nil, fmt.Errorf("please provide the client to operate the Hyper.sh")
-	}
-
-	// FIXME(vdemeester) Remove the context duplication ?
-	p := project.NewProject(context.ClientFactory, &context.Context)
-
-	err := p.Parse()
-	if err != nil {
-		return nil, err
-	}
-
-	if err = context.open(); err != nil {
-		logrus.Errorf("Failed to open project %s: %v", p.Name, err)
-		return nil, err
+		return fmt.Errorf("please provide the client to operate the Hyper.sh")
 	}
 
-	return p, err
+	return nil
 }
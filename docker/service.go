@@ -2,17 +2,21 @@ package docker
 
 import (
 	"fmt"
+	"io"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/docker/engine-api/client"
+	"github.com/docker/engine-api/types"
 	"github.com/docker/go-connections/nat"
 	"github.com/hyperhq/libcompose/config"
 	"github.com/hyperhq/libcompose/labels"
 	"github.com/hyperhq/libcompose/project"
 	"github.com/hyperhq/libcompose/project/options"
 	"github.com/hyperhq/libcompose/utils"
+	"github.com/hyperhq/libcompose/yaml"
 	"golang.org/x/net/context"
 )
 
@@ -47,32 +51,41 @@ func (s *Service) DependentServices() []project.ServiceRelationship {
 	return project.DefaultDependentServices(s.context.Project, s)
 }
 
-// Create implements Service.Create. It ensures the image exists or build it
-// if it can and then create a container.
-func (s *Service) Create(options options.Create) error {
-	containers, err := s.collectContainers()
+// CreateContext implements Service.CreateContext. It ensures the image exists or build it
+// if it can and then create a container, or as many as the scale key of the
+// service.
+func (s *Service) CreateContext(ctx context.Context, options options.Create) error {
+	containers, err := s.collectContainers(ctx)
 	if err != nil {
 		return err
 	}
 
-	imageName, err := s.ensureImageExists(options.NoBuild)
+	imageName, err := s.ensureImageExists(ctx, options.NoBuild)
 	if err != nil {
 		return err
 	}
 
 	if len(containers) != 0 {
-		return s.eachContainer(func(c *Container) error {
-			return s.recreateIfNeeded(imageName, c, options.NoRecreate, options.ForceRecreate)
-		})
+		if err := s.eachContainer(ctx, "create", func(c *Container) error {
+			return s.recreateIfNeeded(ctx, imageName, c, options.NoRecreate, options.ForceRecreate)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := s.checkScale(); err != nil {
+		return err
 	}
 
-	_, err = s.createOne(imageName)
+	if len(containers) < s.scale() {
+		_, err = s.constructContainers(ctx, imageName, s.scale())
+	}
 	return err
 }
 
-func (s *Service) collectContainers() ([]*Container, error) {
+func (s *Service) collectContainers(ctx context.Context) ([]*Container, error) {
 	client := s.context.ClientFactory.Create(s)
-	containers, err := GetContainersByFilter(client, labels.SERVICE.Eq(s.name), labels.PROJECT.Eq(s.context.Project.Name))
+	containers, err := GetContainersByFilter(ctx, client, labels.SERVICE.Eq(s.name), labels.PROJECT.Eq(s.context.Project.Name))
 	if err != nil {
 		return nil, err
 	}
@@ -92,17 +105,8 @@ func (s *Service) collectContainers() ([]*Container, error) {
 	return result, nil
 }
 
-func (s *Service) createOne(imageName string) (*Container, error) {
-	containers, err := s.constructContainers(imageName, 1)
-	if err != nil {
-		return nil, err
-	}
-
-	return containers[0], err
-}
-
-func (s *Service) ensureImageExists(noBuild bool) (string, error) {
-	err := s.imageExists()
+func (s *Service) ensureImageExists(ctx context.Context, noBuild bool) (string, error) {
+	err := s.imageExists(ctx)
 
 	if err == nil {
 		return s.imageName(), nil
@@ -117,17 +121,17 @@ func (s *Service) ensureImageExists(noBuild bool) (string, error) {
 			if noBuild {
 				return "", fmt.Errorf("Service %q needs to be built, but no-build was specified", s.name)
 			}
-			return s.imageName(), s.build(options.Build{})
+			return s.imageName(), s.build(ctx, options.Build{})
 		}
 	*/
 
-	return s.imageName(), s.Pull()
+	return s.imageName(), s.PullContext(ctx)
 }
 
-func (s *Service) imageExists() error {
+func (s *Service) imageExists(ctx context.Context) error {
 	client := s.context.ClientFactory.Create(s)
 
-	_, _, err := client.ImageInspectWithRaw(context.Background(), s.imageName(), false)
+	_, _, err := client.ImageInspectWithRaw(ctx, s.imageName(), false)
 	return err
 }
 
@@ -138,22 +142,22 @@ func (s *Service) imageName() string {
 	return fmt.Sprintf("%s_%s", s.context.ProjectName, s.Name())
 }
 
-// Build implements Service.Build. If an imageName is specified or if the context has
+// BuildContext implements Service.BuildContext. If an imageName is specified or if the context has
 // no build to work with it will do nothing. Otherwise it will try to build
 // the image and returns an error if any.
-func (s *Service) Build(buildOptions options.Build) error {
+func (s *Service) BuildContext(ctx context.Context, buildOptions options.Build) error {
 	if s.Config().Image != "" {
 		return nil
 	}
-	return s.build(buildOptions)
+	return s.build(ctx, buildOptions)
 }
 
-func (s *Service) build(buildOptions options.Build) error {
+func (s *Service) build(ctx context.Context, buildOptions options.Build) error {
 	return nil
 }
 
-func (s *Service) constructContainers(imageName string, count int) ([]*Container, error) {
-	result, err := s.collectContainers()
+func (s *Service) constructContainers(ctx context.Context, imageName string, count int) ([]*Container, error) {
+	result, err := s.collectContainers(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -168,7 +172,7 @@ func (s *Service) constructContainers(imageName string, count int) ([]*Container
 		}
 		namer = NewSingleNamer(s.serviceConfig.ContainerName)
 	} else {
-		namer, err = NewNamer(client, s.context.Project.Name, s.name, false)
+		namer, err = NewNamer(ctx, client, s.context.Project.Name, s.name, false)
 		if err != nil {
 			return nil, err
 		}
@@ -179,70 +183,175 @@ func (s *Service) constructContainers(imageName string, count int) ([]*Container
 
 		c := NewContainer(client, containerName, containerNumber, s)
 
-		dockerContainer, err := c.Create(imageName)
+		dockerContainer, err := c.CreateContext(ctx, imageName)
 		if err != nil {
 			return nil, err
 		}
 
 		logrus.Debugf("Created container %s: %v", dockerContainer.ID, dockerContainer.Name)
 
+		if tx := project.TransactionFromContext(ctx); tx != nil {
+			id := dockerContainer.ID
+			tx.Add(project.NewTransactionStep(nil, func(ctx context.Context) error {
+				_, err := client.ContainerRemove(ctx, id, types.ContainerRemoveOptions{
+					Force: true,
+				})
+				return err
+			}))
+		}
+
 		result = append(result, NewContainer(client, containerName, containerNumber, s))
 	}
 
 	return result, nil
 }
 
-// Up implements Service.Up. It builds the image if needed, creates a container
+// UpContext implements Service.UpContext. It builds the image if needed, creates a container
 // and start it.
-func (s *Service) Up(options options.Up) error {
-	containers, err := s.collectContainers()
+func (s *Service) UpContext(ctx context.Context, options options.Up) error {
+	containers, err := s.collectContainers(ctx)
 	if err != nil {
 		return err
 	}
 
 	var imageName = s.imageName()
 	if len(containers) == 0 || !options.NoRecreate {
-		imageName, err = s.ensureImageExists(options.NoBuild)
+		imageName, err = s.ensureImageExists(ctx, options.NoBuild)
 		if err != nil {
 			return err
 		}
 	}
 
-	return s.up(imageName, true, options)
+	return s.up(ctx, imageName, true, options)
+}
+
+// Exec implements Service.Exec. It executes a command in the running container
+// of the service with the specified number.
+func (s *Service) Exec(ctx context.Context, number int, commandParts []string, options options.Exec) (int, error) {
+	c, err := s.containerNumbered(ctx, number)
+	if err != nil {
+		return -1, err
+	}
+
+	return c.Exec(ctx, commandParts, options)
+}
+
+// CopyTo implements Service.CopyTo. It copies the specified tar stream to the
+// specified path in the container with the specified number.
+func (s *Service) CopyTo(ctx context.Context, number int, path string, content io.Reader) error {
+	c, err := s.containerNumbered(ctx, number)
+	if err != nil {
+		return err
+	}
+
+	return c.CopyTo(ctx, path, content)
+}
+
+// CopyFrom implements Service.CopyFrom. It returns a tar stream of the
+// specified path in the container with the specified number.
+func (s *Service) CopyFrom(ctx context.Context, number int, path string) (io.ReadCloser, error) {
+	c, err := s.containerNumbered(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.CopyFrom(ctx, path)
+}
+
+// containerNumbered returns the container of the service with the specified
+// number.
+func (s *Service) containerNumbered(ctx context.Context, number int) (*Container, error) {
+	containers, err := s.collectContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range containers {
+		if c.containerNumber == number {
+			return c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Service %s has no container number %d", s.name, number)
 }
 
-// Run implements Service.Run. It runs a one of command within the service container.
+// Run implements Service.Run. It runs a one of command within the service
+// container, with the default options.
 func (s *Service) Run(ctx context.Context, commandParts []string) (int, error) {
-	imageName, err := s.ensureImageExists(false)
+	return s.RunWithOptions(ctx, commandParts, options.Run{})
+}
+
+// RunWithOptions implements Service.RunWithOptions. It runs a one of command
+// within the service container, with the specified options.
+func (s *Service) RunWithOptions(ctx context.Context, commandParts []string, options options.Run) (int, error) {
+	imageName, err := s.ensureImageExists(ctx, false)
 	if err != nil {
 		return -1, err
 	}
 
 	client := s.context.ClientFactory.Create(s)
 
-	namer, err := NewNamer(client, s.context.Project.Name, s.name, true)
+	namer, err := NewNamer(ctx, client, s.context.Project.Name, s.name, true)
 	if err != nil {
 		return -1, err
 	}
 
 	containerName, containerNumber := namer.Next()
+	if options.Name != "" {
+		containerName = options.Name
+	}
 
 	c := NewOneOffContainer(client, containerName, containerNumber, s)
 
-	return c.Run(ctx, imageName, &config.ServiceConfig{Command: commandParts, Tty: true, StdinOpen: true})
+	return c.Run(ctx, imageName, s.runConfig(commandParts, options), options)
+}
+
+// overrideConfig returns a copy of the service configuration, with the
+// command, tty and stdin_open of the specified one, or the service
+// configuration itself if nil.
+func (s *Service) overrideConfig(configOverride *config.ServiceConfig) *config.ServiceConfig {
+	if configOverride == nil {
+		return s.serviceConfig
+	}
+	serviceConfig := *s.serviceConfig
+	serviceConfig.Command = configOverride.Command
+	serviceConfig.Tty = configOverride.Tty
+	serviceConfig.StdinOpen = configOverride.StdinOpen
+	return &serviceConfig
+}
+
+// runConfig returns a copy of the service configuration, with the command and
+// the options of a one-off container applied.
+func (s *Service) runConfig(commandParts []string, options options.Run) *config.ServiceConfig {
+	serviceConfig := *s.serviceConfig
+	serviceConfig.Tty = !options.DisableTty
+	serviceConfig.StdinOpen = !options.DisableTty || options.Interactive
+	if len(commandParts) != 0 {
+		serviceConfig.Command = commandParts
+	}
+	if len(options.Env) != 0 {
+		serviceConfig.Environment = append(append(yaml.MaporEqualSlice{}, serviceConfig.Environment...), options.Env...)
+	}
+	if options.WorkingDir != "" {
+		serviceConfig.WorkingDir = options.WorkingDir
+	}
+	if len(options.Entrypoint) != 0 {
+		serviceConfig.Entrypoint = options.Entrypoint
+	}
+	return &serviceConfig
 }
 
-// Info implements Service.Info. It returns an project.InfoSet with the containers
+// InfoContext implements Service.InfoContext. It returns an project.InfoSet with the containers
 // related to this service (can be multiple if using the scale command).
-func (s *Service) Info(qFlag bool) (project.InfoSet, error) {
+func (s *Service) InfoContext(ctx context.Context, qFlag bool) (project.InfoSet, error) {
 	result := project.InfoSet{}
-	containers, err := s.collectContainers()
+	containers, err := s.collectContainers(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, c := range containers {
-		info, err := c.Info(qFlag)
+		info, err := c.InfoContext(ctx, qFlag)
 		if err != nil {
 			return nil, err
 		}
@@ -252,43 +361,76 @@ func (s *Service) Info(qFlag bool) (project.InfoSet, error) {
 	return result, nil
 }
 
-// Start implements Service.Start. It tries to start a container without creating it.
-func (s *Service) Start() error {
-	return s.up("", false, options.Up{})
+// StartContext implements Service.StartContext. It tries to start a container without creating it.
+func (s *Service) StartContext(ctx context.Context) error {
+	return s.up(ctx, "", false, options.Up{})
 }
 
-func (s *Service) up(imageName string, create bool, options options.Up) error {
-	containers, err := s.collectContainers()
+func (s *Service) up(ctx context.Context, imageName string, create bool, options options.Up) error {
+	containers, err := s.collectContainers(ctx)
 	if err != nil {
 		return err
 	}
 
 	logrus.Debugf("Found %d existing containers for service %s", len(containers), s.name)
 
-	if len(containers) == 0 && create {
-		c, err := s.createOne(imageName)
-		if err != nil {
+	if create {
+		if err := s.checkScale(); err != nil {
+			return err
+		}
+		// The scale key of the service, if any, sets the number of replicas
+		if s.serviceConfig.Scale > 0 {
+			timeout := options.Timeout
+			if timeout == 0 {
+				timeout = defaultScaleDownTimeout
+			}
+			if containers, err = s.scaleDown(ctx, containers, s.serviceConfig.Scale, timeout); err != nil {
+				return err
+			}
+		}
+		if len(containers) < s.scale() {
+			if containers, err = s.constructContainers(ctx, imageName, s.scale()); err != nil {
+				return err
+			}
+		}
+	}
+
+	// With an update strategy, the containers are recreated in batches before
+	// being started.
+	rolling := create && !options.NoRecreate && s.serviceConfig.UpdateConfig != nil
+	if rolling {
+		if err := s.rollingRecreate(ctx, imageName, containers, options.ForceRecreate); err != nil {
 			return err
 		}
-		containers = []*Container{c}
 	}
 
-	return s.eachContainer(func(c *Container) error {
-		if create {
-			if err := s.recreateIfNeeded(imageName, c, options.NoRecreate, options.ForceRecreate); err != nil {
+	operation := "start"
+	if create {
+		operation = "up"
+	}
+
+	action := func(c *Container) error {
+		if create && !rolling {
+			if err := s.recreateIfNeeded(ctx, imageName, c, options.NoRecreate, options.ForceRecreate); err != nil {
 				return err
 			}
 		}
 
-		return c.Up(imageName)
-	})
+		return c.UpContext(ctx, imageName)
+	}
+	if create {
+		// Only the containers kept by the scale, in a transaction the ones
+		// above it are stopped until the commit removes them.
+		return s.forContainers(containers, operation, action)
+	}
+	return s.eachContainer(ctx, operation, action)
 }
 
-func (s *Service) recreateIfNeeded(imageName string, c *Container, noRecreate, forceRecreate bool) error {
+func (s *Service) recreateIfNeeded(ctx context.Context, imageName string, c *Container, noRecreate, forceRecreate bool) error {
 	if noRecreate {
 		return nil
 	}
-	outOfSync, err := c.OutOfSync(imageName)
+	outOfSync, err := c.OutOfSyncContext(ctx, imageName)
 	if err != nil {
 		return err
 	}
@@ -300,7 +442,7 @@ func (s *Service) recreateIfNeeded(imageName string, c *Container, noRecreate, f
 
 	if forceRecreate || outOfSync {
 		logrus.Infof("Recreating %s", s.name)
-		if _, err := c.Recreate(imageName); err != nil {
+		if _, err := c.RecreateContext(ctx, imageName); err != nil {
 			return err
 		}
 	}
@@ -308,148 +450,370 @@ func (s *Service) recreateIfNeeded(imageName string, c *Container, noRecreate, f
 	return nil
 }
 
-func (s *Service) eachContainer(action func(*Container) error) error {
-	containers, err := s.collectContainers()
+// eachContainer runs the specified operation on every container of the service
+// in parallel. Failures are returned as a *project.MultiError with one
+// *project.ServiceError per failed container.
+func (s *Service) eachContainer(ctx context.Context, operation string, action func(*Container) error) error {
+	containers, err := s.collectContainers(ctx)
 	if err != nil {
 		return err
 	}
 
-	tasks := utils.InParallel{}
+	return s.forContainers(containers, operation, action)
+}
+
+// forContainers runs the specified operation on the specified containers in
+// parallel, and reports failures like eachContainer.
+func (s *Service) forContainers(containers []*Container, operation string, action func(*Container) error) error {
+	tasks := utils.NewInParallel(s.context.ContainerParallelism)
 	for _, container := range containers {
 		task := func(container *Container) func() error {
 			return func() error {
-				return action(container)
+				if err := action(container); err != nil {
+					return &project.ServiceError{
+						Service:   s.name,
+						Container: container.Name(),
+						Operation: operation,
+						Err:       err,
+					}
+				}
+				return nil
 			}
 		}(container)
 
 		tasks.Add(task)
 	}
 
-	return tasks.Wait()
+	if err := tasks.Wait(); err != nil {
+		return project.NewMultiError(tasks.Errors()...)
+	}
+	return nil
 }
 
-// Stop implements Service.Stop. It stops any containers related to the service.
-func (s *Service) Stop(timeout int) error {
-	return s.eachContainer(func(c *Container) error {
-		return c.Stop(timeout)
+// StopContext implements Service.StopContext. It stops any containers related to the service.
+func (s *Service) StopContext(ctx context.Context, timeout int) error {
+	return s.eachContainer(ctx, "stop", func(c *Container) error {
+		return c.StopContext(ctx, timeout)
 	})
 }
 
-// Restart implements Service.Restart. It restarts any containers related to the service.
-func (s *Service) Restart(timeout int) error {
-	return s.eachContainer(func(c *Container) error {
-		return c.Restart(timeout)
+// RestartContext implements Service.RestartContext. It restarts any containers related to the service.
+func (s *Service) RestartContext(ctx context.Context, timeout int) error {
+	return s.eachContainer(ctx, "restart", func(c *Container) error {
+		return c.RestartContext(ctx, timeout)
 	})
 }
 
-// Kill implements Service.Kill. It kills any containers related to the service.
-func (s *Service) Kill(signal string) error {
-	return s.eachContainer(func(c *Container) error {
-		return c.Kill(signal)
+// KillContext implements Service.KillContext. It kills any containers related to the service.
+func (s *Service) KillContext(ctx context.Context, signal string) error {
+	return s.eachContainer(ctx, "kill", func(c *Container) error {
+		return c.KillContext(ctx, signal)
 	})
 }
 
-// Delete implements Service.Delete. It removes any containers related to the service.
-func (s *Service) Delete(options options.Delete) error {
-	return s.eachContainer(func(c *Container) error {
-		return c.Delete(options.RemoveVolume)
+// DeleteContext implements Service.DeleteContext. It removes any containers related to the service.
+func (s *Service) DeleteContext(ctx context.Context, options options.Delete) error {
+	return s.eachContainer(ctx, "delete", func(c *Container) error {
+		return c.DeleteContext(ctx, options.RemoveVolume)
 	})
 }
 
-// Log implements Service.Log. It returns the docker logs for each container related to the service.
-func (s *Service) Log(follow bool) error {
-	return s.eachContainer(func(c *Container) error {
-		return c.Log(follow)
+// LogContext implements Service.LogContext. It returns the docker logs for each container related to the service.
+func (s *Service) LogContext(ctx context.Context, options options.Log) error {
+	return s.eachContainer(ctx, "log", func(c *Container) error {
+		if options.Number != 0 && c.containerNumber != options.Number {
+			return nil
+		}
+		return c.LogContext(ctx, options)
 	})
 }
 
-// Scale implements Service.Scale. It creates or removes containers to have the specified number
-// of related container to the service to run.
-func (s *Service) Scale(scale int, timeout int) error {
+// ScaleContext implements Service.ScaleContext. It creates or removes containers to have the specified number
+// of related container to the service to run. The highest container numbers are removed first.
+func (s *Service) ScaleContext(ctx context.Context, scale int, timeout int) error {
 	if s.specificiesHostPort() {
 		logrus.Warnf("The \"%s\" service specifies a port on the host. If multiple containers for this service are created on a single host, the port will clash.", s.Name())
 	}
 
-	foundCount := 0
-	err := s.eachContainer(func(c *Container) error {
-		foundCount++
-		if foundCount > scale {
-			err := c.Stop(timeout)
-			if err != nil {
+	containers, err := s.collectContainers(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.scaleDown(ctx, containers, scale, timeout); err != nil {
+		return err
+	}
+
+	if len(containers) < scale {
+		imageName, err := s.ensureImageExists(ctx, false)
+		if err != nil {
+			return err
+		}
+
+		if _, err = s.constructContainers(ctx, imageName, scale); err != nil {
+			return err
+		}
+	}
+
+	return s.up(ctx, "", false, options.Up{})
+}
+
+// scale returns the number of replicas Up creates, set by the scale key of
+// the service, one by default.
+func (s *Service) scale() int {
+	if s.serviceConfig.Scale > 0 {
+		return s.serviceConfig.Scale
+	}
+	return 1
+}
+
+// checkScale returns an error if the service sets both a container name and
+// a scale key above one, as the container names have to be unique.
+func (s *Service) checkScale() error {
+	if s.serviceConfig.ContainerName != "" && s.serviceConfig.Scale > 1 {
+		return fmt.Errorf("Service %s sets the container name %s and a scale of %d, remove the custom name to scale the service", s.name, s.serviceConfig.ContainerName, s.serviceConfig.Scale)
+	}
+	return nil
+}
+
+// defaultScaleDownTimeout is the timeout, in seconds, to stop the containers
+// Up removes to match the scale key, if options.Up does not set one.
+const defaultScaleDownTimeout = 10
+
+// scaleDown stops and removes the containers above the specified scale, the
+// ones with the highest numbers. It returns the containers kept, sorted by
+// container number.
+//
+// In a transactional operation, the containers are only stopped, and are
+// removed when the transaction is committed or restarted on rollback.
+func (s *Service) scaleDown(ctx context.Context, containers []*Container, scale int, timeout int) ([]*Container, error) {
+	sorted := append([]*Container{}, containers...)
+	sort.Sort(byContainerNumber(sorted))
+	if len(sorted) <= scale {
+		return sorted, nil
+	}
+
+	tx := project.TransactionFromContext(ctx)
+	err := s.forContainers(sorted[scale:], "scale", func(c *Container) error {
+		if tx == nil {
+			if err := c.StopContext(ctx, timeout); err != nil {
 				return err
 			}
 			// FIXME(vdemeester) remove volume in scale by default ?
-			return c.Delete(false)
+			return c.DeleteContext(ctx, false)
+		}
+
+		running, err := c.IsRunningContext(ctx)
+		if err != nil {
+			return err
+		}
+		if err := c.StopContext(ctx, timeout); err != nil {
+			return err
 		}
+		tx.Add(project.NewTransactionStep(func(ctx context.Context) error {
+			return c.DeleteContext(ctx, false)
+		}, func(ctx context.Context) error {
+			if !running {
+				return nil
+			}
+			logrus.Infof("Restarting %s", c.Name())
+			return c.UpContext(ctx, "")
+		}))
 		return nil
 	})
+	return sorted[:scale], err
+}
 
+// PlanUp implements Service.PlanUp. It returns the actions Up would perform on
+// the containers of the service, without executing them.
+func (s *Service) PlanUp(ctx context.Context, options options.Up) ([]project.Action, error) {
+	containers, err := s.collectContainers(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	sort.Sort(byContainerNumber(containers))
+
+	actions := []project.Action{}
+	if scale := s.serviceConfig.Scale; scale > 0 && len(containers) > scale {
+		if actions, err = planRemove(ctx, actions, containers[scale:], fmt.Sprintf("scale is %d", scale)); err != nil {
+			return nil, err
+		}
+		containers = containers[:scale]
+	}
+
+	for _, c := range containers {
+		reason := ""
+		if !options.NoRecreate {
+			if options.ForceRecreate {
+				reason = "force-recreate was specified"
+			} else if reason, err = c.outOfSyncReason(ctx, s.imageName()); err != nil {
+				return nil, err
+			}
+		}
+		if reason != "" {
+			actions = append(actions,
+				project.Action{Type: project.ActionRecreate, Container: c.Name(), Reason: reason},
+				project.Action{Type: project.ActionStart, Container: c.Name(), Reason: "container was recreated"})
+			continue
+		}
 
-	if foundCount != scale {
-		imageName, err := s.ensureImageExists(false)
+		running, err := c.IsRunningContext(ctx)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if !running {
+			actions = append(actions, project.Action{Type: project.ActionStart, Container: c.Name(), Reason: "container is not running"})
 		}
+	}
 
-		if _, err = s.constructContainers(imageName, scale); err != nil {
-			return err
+	if len(containers) < s.scale() {
+		reason := fmt.Sprintf("scale is %d", s.scale())
+		if len(containers) == 0 {
+			reason = "no existing container"
+		}
+		if actions, err = s.planCreate(ctx, actions, s.scale()-len(containers), reason); err != nil {
+			return nil, err
+		}
+	}
+
+	return actions, nil
+}
+
+// PlanScale implements Service.PlanScale. It returns the actions Scale would
+// perform on the containers of the service, without executing them.
+func (s *Service) PlanScale(ctx context.Context, scale int) ([]project.Action, error) {
+	containers, err := s.collectContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(byContainerNumber(containers))
+
+	actions := []project.Action{}
+	if len(containers) > scale {
+		if actions, err = planRemove(ctx, actions, containers[scale:], fmt.Sprintf("scaling down to %d", scale)); err != nil {
+			return nil, err
+		}
+		containers = containers[:scale]
+	}
+
+	for _, c := range containers {
+		running, err := c.IsRunningContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !running {
+			actions = append(actions, project.Action{Type: project.ActionStart, Container: c.Name(), Reason: "container is not running"})
+		}
+	}
+
+	if len(containers) < scale {
+		if actions, err = s.planCreate(ctx, actions, scale-len(containers), fmt.Sprintf("scaling up to %d", scale)); err != nil {
+			return nil, err
+		}
+	}
+
+	return actions, nil
+}
+
+// planRemove appends the actions stopping, if running, and removing the
+// specified containers to the specified ones.
+func planRemove(ctx context.Context, actions []project.Action, containers []*Container, reason string) ([]project.Action, error) {
+	for _, c := range containers {
+		running, err := c.IsRunningContext(ctx)
+		if err != nil {
+			return nil, err
 		}
+		if running {
+			actions = append(actions, project.Action{Type: project.ActionStop, Container: c.Name(), Reason: reason})
+		}
+		actions = append(actions, project.Action{Type: project.ActionRemove, Container: c.Name(), Reason: reason})
+	}
+	return actions, nil
+}
+
+// planCreate appends the actions creating and starting count new containers
+// to the specified ones.
+func (s *Service) planCreate(ctx context.Context, actions []project.Action, count int, reason string) ([]project.Action, error) {
+	names, err := s.nextContainerNames(ctx, count)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		actions = append(actions,
+			project.Action{Type: project.ActionCreate, Container: name, Reason: reason},
+			project.Action{Type: project.ActionStart, Container: name, Reason: "container was created"})
+	}
+	return actions, nil
+}
+
+// nextContainerNames returns the names the next count containers of the
+// service would be created with.
+func (s *Service) nextContainerNames(ctx context.Context, count int) ([]string, error) {
+	if s.serviceConfig.ContainerName != "" {
+		return []string{s.serviceConfig.ContainerName}, nil
 	}
 
-	return s.up("", false, options.Up{})
+	namer, err := NewNamer(ctx, s.context.ClientFactory.Create(s), s.context.Project.Name, s.name, false)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for i := 0; i < count; i++ {
+		name, _ := namer.Next()
+		names = append(names, name)
+	}
+	return names, nil
 }
 
-// Pull implements Service.Pull. It pulls the image of the service and skip the service that
+// PullContext implements Service.PullContext. It pulls the image of the service and skip the service that
 // would need to be built.
-func (s *Service) Pull() error {
+func (s *Service) PullContext(ctx context.Context) error {
 	if s.Config().Image == "" {
 		return nil
 	}
 
-	return pullImage(s.context.ClientFactory.Create(s), s, s.Config().Image)
+	return pullImage(ctx, s.context.ClientFactory.Create(s), s, s.Config().Image)
 }
 
-// Pause implements Service.Pause. It puts into pause the container(s) related
+// PauseContext implements Service.PauseContext. It puts into pause the container(s) related
 // to the service.
-func (s *Service) Pause() error {
-	return s.eachContainer(func(c *Container) error {
-		return c.Pause()
+func (s *Service) PauseContext(ctx context.Context) error {
+	return s.eachContainer(ctx, "pause", func(c *Container) error {
+		return c.PauseContext(ctx)
 	})
 }
 
-// Unpause implements Service.Pause. It brings back from pause the container(s)
+// UnpauseContext implements Service.PauseContext. It brings back from pause the container(s)
 // related to the service.
-func (s *Service) Unpause() error {
-	return s.eachContainer(func(c *Container) error {
-		return c.Unpause()
+func (s *Service) UnpauseContext(ctx context.Context) error {
+	return s.eachContainer(ctx, "unpause", func(c *Container) error {
+		return c.UnpauseContext(ctx)
 	})
 }
 
-// RemoveImage implements Service.RemoveImage. It removes images used for the service
+// RemoveImageContext implements Service.RemoveImageContext. It removes images used for the service
 // depending on the specified type.
-func (s *Service) RemoveImage(imageType options.ImageType) error {
+func (s *Service) RemoveImageContext(ctx context.Context, imageType options.ImageType) error {
 	switch imageType {
 	case "local":
 		if s.Config().Image != "" {
 			return nil
 		}
-		return removeImage(s.context.ClientFactory.Create(s), s.imageName())
+		return removeImage(ctx, s.context.ClientFactory.Create(s), s.imageName())
 	case "all":
-		return removeImage(s.context.ClientFactory.Create(s), s.imageName())
+		return removeImage(ctx, s.context.ClientFactory.Create(s), s.imageName())
 	default:
 		// Don't do a thing, should be validated up-front
 		return nil
 	}
 }
 
-// Containers implements Service.Containers. It returns the list of containers
+// ContainersContext implements Service.ContainersContext. It returns the list of containers
 // that are related to the service.
-func (s *Service) Containers() ([]project.Container, error) {
+func (s *Service) ContainersContext(ctx context.Context) ([]project.Container, error) {
 	result := []project.Container{}
-	containers, err := s.collectContainers()
+	containers, err := s.collectContainers(ctx)
 	if err != nil {
 		return nil, err
 	}
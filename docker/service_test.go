@@ -1,5 +1,198 @@
 package docker
 
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project"
+	"github.com/hyperhq/libcompose/project/options"
+	"github.com/hyperhq/libcompose/yaml"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestRunConfig(t *testing.T) {
+	service := &Service{
+		name: "web",
+		serviceConfig: &config.ServiceConfig{
+			Image:       "nginx",
+			Command:     yaml.Command{"nginx"},
+			Environment: yaml.MaporEqualSlice{"A=1"},
+			WorkingDir:  "/srv",
+		},
+	}
+	hash := config.GetServiceHash(service.name, service.serviceConfig)
+
+	runConfig := service.runConfig([]string{"ls", "-l"}, options.Run{
+		Env:        []string{"B=2"},
+		WorkingDir: "/tmp",
+		Entrypoint: []string{"/bin/sh", "-c"},
+	})
+	assert.Equal(t, &config.ServiceConfig{
+		Image:       "nginx",
+		Command:     yaml.Command{"ls", "-l"},
+		Entrypoint:  yaml.Command{"/bin/sh", "-c"},
+		Environment: yaml.MaporEqualSlice{"A=1", "B=2"},
+		WorkingDir:  "/tmp",
+		Tty:         true,
+		StdinOpen:   true,
+	}, runConfig)
+
+	assert.Equal(t, &config.ServiceConfig{
+		Image:       "nginx",
+		Command:     yaml.Command{"nginx"},
+		Environment: yaml.MaporEqualSlice{"A=1"},
+		WorkingDir:  "/srv",
+	}, service.serviceConfig)
+	assert.Equal(t, hash, config.GetServiceHash(service.name, service.serviceConfig))
+}
+
+func TestScaleDown(t *testing.T) {
+	client := &FakeClient{running: map[string]bool{}}
+	service := &Service{
+		name:          "web",
+		serviceConfig: &config.ServiceConfig{},
+		context:       &Context{},
+	}
+
+	containers := []*Container{}
+	for _, number := range []int{3, 1, 5, 2, 4} {
+		name := fmt.Sprintf("project_web_%d", number)
+		client.running[name] = true
+		containers = append(containers, &Container{name: name, containerNumber: number, client: client, service: service})
+	}
+
+	kept, err := service.scaleDown(context.Background(), containers, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []int{1, 2}, []int{kept[0].containerNumber, kept[1].containerNumber})
+	assert.ElementsMatch(t, []string{"project_web_3", "project_web_4", "project_web_5"}, client.called("remove"))
+
+	assert.Equal(t, 1, service.scale())
+	service.serviceConfig.Scale = 3
+	assert.Equal(t, 3, service.scale())
+
+	service.serviceConfig.ContainerName = "web"
+	assert.NotNil(t, service.checkScale())
+	service.serviceConfig.Scale = 1
+	assert.Nil(t, service.checkScale())
+}
+
+func TestScaleDownInTransaction(t *testing.T) {
+	client := &FakeClient{running: map[string]bool{}}
+	service := &Service{
+		name:          "web",
+		serviceConfig: &config.ServiceConfig{},
+		context:       &Context{},
+	}
+
+	containers := []*Container{}
+	for _, number := range []int{1, 2, 3} {
+		name := fmt.Sprintf("project_web_%d", number)
+		client.running[name] = true
+		containers = append(containers, &Container{name: name, containerNumber: number, client: client, service: service, eventNotifier: &project.Project{}})
+	}
+
+	tx := &project.Transaction{}
+	if _, err := service.scaleDown(project.WithTransaction(context.Background(), tx), containers, 1, 10); err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, client.called("remove"))
+	assert.Equal(t, map[string]bool{"project_web_1": true, "project_web_2": false, "project_web_3": false}, client.running)
+
+	if err := tx.Rollback(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, client.called("remove"))
+	assert.Equal(t, map[string]bool{"project_web_1": true, "project_web_2": true, "project_web_3": true}, client.running)
+
+	tx = &project.Transaction{}
+	if _, err := service.scaleDown(project.WithTransaction(context.Background(), tx), containers, 1, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assert.ElementsMatch(t, []string{"project_web_2", "project_web_3"}, client.called("remove"))
+}
+
+func TestUpScaleDownInTransaction(t *testing.T) {
+	client := &FakeClient{running: map[string]bool{}}
+	for _, number := range []int{1, 2, 3} {
+		client.running[fmt.Sprintf("project_web_%d", number)] = true
+	}
+	service := &Service{
+		name:          "web",
+		serviceConfig: &config.ServiceConfig{Scale: 1},
+		context: &Context{
+			Context: project.Context{
+				Project: &project.Project{Name: "project", ServiceConfigs: config.NewServiceConfigs()},
+			},
+			ClientFactory: client,
+		},
+	}
+
+	tx := &project.Transaction{}
+	if err := service.up(project.WithTransaction(context.Background(), tx), "", true, options.Up{Create: options.Create{NoRecreate: true}}); err != nil {
+		t.Fatal(err)
+	}
+	// The containers above the scale are not restarted by the up
+	assert.Empty(t, client.called("start"))
+	assert.Empty(t, client.called("remove"))
+	assert.Equal(t, map[string]bool{"project_web_1": true, "project_web_2": false, "project_web_3": false}, client.running)
+
+	if err := tx.Commit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assert.ElementsMatch(t, []string{"project_web_2", "project_web_3"}, client.called("remove"))
+	assert.Empty(t, client.called("start"))
+}
+
+func TestContainerNumbered(t *testing.T) {
+	// project_web_2 was removed by a scale down, the number 2 is not the
+	// second container anymore.
+	client := &FakeClient{running: map[string]bool{"project_web_1": true, "project_web_3": true}}
+	service := &Service{
+		name: "web",
+		context: &Context{
+			Context: project.Context{
+				Project: &project.Project{Name: "project", ServiceConfigs: config.NewServiceConfigs()},
+			},
+			ClientFactory: client,
+		},
+	}
+
+	if _, err := service.Exec(context.Background(), 3, []string{"ls"}, options.Exec{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"project_web_3"}, client.called("exec"))
+
+	if err := service.CopyTo(context.Background(), 3, "/fixtures", strings.NewReader("tar")); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{"id-project_web_3:/fixtures": "tar"}, client.copied)
+
+	content, err := service.CopyFrom(context.Background(), 3, "/fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content.Close()
+
+	if _, err := service.Exec(context.Background(), 2, []string{"ls"}, options.Exec{}); err == nil {
+		t.Fatal("expected an error for the removed container number 2")
+	}
+	if err := service.CopyTo(context.Background(), 2, "/fixtures", strings.NewReader("tar")); err == nil {
+		t.Fatal("expected an error for the removed container number 2")
+	}
+	if _, err := service.CopyFrom(context.Background(), 2, "/fixtures"); err == nil {
+		t.Fatal("expected an error for the removed container number 2")
+	}
+}
+
 /*
 func TestSpecifiesHostPort(t *testing.T) {
 	servicesWithHostPort := []Service{
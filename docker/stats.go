@@ -0,0 +1,111 @@
+package docker
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/docker/engine-api/types"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+
+	"github.com/hyperhq/libcompose/project"
+)
+
+// Top implements Container.Top. It lists the processes running in the
+// container, if it is running.
+func (c *Container) Top(ctx context.Context) (*project.ContainerProcesses, error) {
+	container, err := c.findExisting(ctx)
+	if err != nil || container == nil || !container.State.Running {
+		return nil, err
+	}
+
+	processList, err := c.client.ContainerTop(ctx, container.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &project.ContainerProcesses{
+		Service:   c.serviceName,
+		Number:    c.containerNumber,
+		Name:      c.name,
+		Titles:    processList.Titles,
+		Processes: processList.Processes,
+	}, nil
+}
+
+// Stats implements Container.Stats. It streams the resource usage of the
+// container, if it is running.
+func (c *Container) Stats(ctx context.Context) (<-chan project.ContainerStats, error) {
+	container, err := c.findExisting(ctx)
+	if err != nil || container == nil || !container.State.Running {
+		return nil, err
+	}
+
+	body, err := c.client.ContainerStats(ctx, container.ID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan project.ContainerStats)
+	go func() {
+		defer close(out)
+		defer body.Close()
+
+		decoder := json.NewDecoder(body)
+		for {
+			var stats types.StatsJSON
+			if err := decoder.Decode(&stats); err != nil {
+				if err != io.EOF && ctx.Err() == nil {
+					logrus.Errorf("Failed to decode stats of %s: %v", c.name, err)
+				}
+				return
+			}
+
+			select {
+			case out <- project.ContainerStats{
+				Service:       c.serviceName,
+				Number:        c.containerNumber,
+				ID:            container.ID,
+				Name:          c.name,
+				ResourceUsage: resourceUsage(&stats),
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// resourceUsage computes the resource usage from the stats of the engine, the
+// same way docker stats does.
+func resourceUsage(stats *types.StatsJSON) project.ResourceUsage {
+	usage := project.ResourceUsage{
+		MemoryUsage: stats.MemoryStats.Usage,
+		MemoryLimit: stats.MemoryStats.Limit,
+	}
+
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	if cpuDelta > 0 && systemDelta > 0 {
+		usage.CPUPercent = cpuDelta / systemDelta * float64(len(stats.CPUStats.CPUUsage.PercpuUsage)) * 100
+	}
+
+	for _, network := range stats.Networks {
+		usage.NetworkRx += network.RxBytes
+		usage.NetworkTx += network.TxBytes
+	}
+
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			usage.BlockRead += entry.Value
+		case "write":
+			usage.BlockWrite += entry.Value
+		}
+	}
+
+	return usage
+}
@@ -0,0 +1,51 @@
+package docker
+
+import (
+	"github.com/docker/engine-api/types"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// recreateStep records the recreation of a container in a transaction. The
+// replaced container is kept, renamed and possibly stopped, until the
+// transaction is committed.
+type recreateStep struct {
+	container *Container
+	oldID     string
+	newID     string
+	name      string
+	stopped   bool
+}
+
+// Commit removes the replaced container.
+func (s *recreateStep) Commit(ctx context.Context) error {
+	if _, err := s.container.client.ContainerRemove(ctx, s.oldID, types.ContainerRemoveOptions{
+		Force: true,
+	}); err != nil {
+		logrus.Errorf("Failed to remove old container %s", s.name)
+		return err
+	}
+	logrus.Debugf("Removed old container %s %s", s.name, s.oldID)
+	return nil
+}
+
+// Rollback removes the replacement container, if any, and restores the
+// replaced one: its name and, if it was stopped, its running state.
+func (s *recreateStep) Rollback(ctx context.Context) error {
+	client := s.container.client
+	if s.newID != "" {
+		if _, err := client.ContainerRemove(ctx, s.newID, types.ContainerRemoveOptions{
+			Force: true,
+		}); err != nil {
+			return err
+		}
+	}
+	if err := client.ContainerRename(ctx, s.oldID, s.name); err != nil {
+		return err
+	}
+	logrus.Infof("Restored container %s", s.name)
+	if s.stopped {
+		return client.ContainerStart(ctx, s.oldID, "")
+	}
+	return nil
+}
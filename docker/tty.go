@@ -0,0 +1,98 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/docker/engine-api/types"
+	"github.com/hyperhq/hypercli/pkg/term"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// ignoredSignals are the signals never forwarded to a container: they are
+// either about the process itself, or handled apart like SIGWINCH.
+var ignoredSignals = map[os.Signal]bool{
+	syscall.SIGCHLD:  true,
+	syscall.SIGPIPE:  true,
+	syscall.SIGURG:   true,
+	syscall.SIGWINCH: true,
+}
+
+// forwardSignals sends the signals received by the process to the specified
+// container with ContainerKill, until the returned function is called.
+func (c *Container) forwardSignals(ctx context.Context, id string) func() {
+	sigc := make(chan os.Signal, 128)
+	signal.Notify(sigc)
+	go proxySignals(sigc, func(sig string) error {
+		return c.client.ContainerKill(ctx, id, sig)
+	})
+	return func() {
+		signal.Stop(sigc)
+		close(sigc)
+	}
+}
+
+// proxySignals calls kill with the number of each signal received on the
+// specified channel, until it is closed.
+func proxySignals(sigc <-chan os.Signal, kill func(sig string) error) {
+	for s := range sigc {
+		sig, ok := s.(syscall.Signal)
+		if !ok || ignoredSignals[s] {
+			continue
+		}
+		if err := kill(fmt.Sprint(int(sig))); err != nil {
+			logrus.Debugf("Failed to forward signal %v: %v", s, err)
+		}
+	}
+}
+
+// monitorTtySize resizes the tty with the specified function to the size of
+// the specified terminal, now and each time it is resized, until the returned
+// function is called.
+func monitorTtySize(ctx context.Context, fd uintptr, resize func(ctx context.Context, options types.ResizeOptions) error) func() {
+	resizeTty(ctx, fd, resize)
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGWINCH)
+	go func() {
+		for range sigc {
+			resizeTty(ctx, fd, resize)
+		}
+	}()
+	return func() {
+		signal.Stop(sigc)
+		close(sigc)
+	}
+}
+
+func resizeTty(ctx context.Context, fd uintptr, resize func(ctx context.Context, options types.ResizeOptions) error) {
+	ws, err := term.GetWinsize(fd)
+	if err != nil {
+		logrus.Debugf("Failed to get the terminal size: %v", err)
+		return
+	}
+	if ws.Height == 0 && ws.Width == 0 {
+		return
+	}
+	if err := resize(ctx, types.ResizeOptions{
+		Height: int(ws.Height),
+		Width:  int(ws.Width),
+	}); err != nil {
+		logrus.Debugf("Failed to resize the tty: %v", err)
+	}
+}
+
+// validateDetachKeys checks the specified detach keys, like ctrl-p,ctrl-q,
+// can be parsed. Empty keys use the engine default.
+func validateDetachKeys(keys string) error {
+	if keys == "" {
+		return nil
+	}
+	if _, err := term.ToBytes(keys); err != nil {
+		return fmt.Errorf("Invalid detach keys %q: %v", keys, err)
+	}
+	return nil
+}
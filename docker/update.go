@@ -0,0 +1,165 @@
+package docker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/docker/engine-api/types"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+
+	"github.com/hyperhq/libcompose/project"
+	"github.com/hyperhq/libcompose/utils"
+)
+
+// Update orders and failure actions of the update_config service key.
+const (
+	UpdateOrderStopFirst  = "stop-first"
+	UpdateOrderStartFirst = "start-first"
+
+	UpdateFailureActionPause    = "pause"
+	UpdateFailureActionContinue = "continue"
+)
+
+// readyPollInterval is the interval at which a new container is checked while
+// waiting for it to be running, or healthy.
+var readyPollInterval = time.Second
+
+// rollingRecreate recreates the out of sync containers (or all of them if
+// forceRecreate is set) in batches, following the update_config of the
+// service. Each new container is started and has to be running, or healthy if
+// it has a healthcheck, before the next batch is recreated.
+func (s *Service) rollingRecreate(ctx context.Context, imageName string, containers []*Container, forceRecreate bool) error {
+	updateConfig := s.serviceConfig.UpdateConfig
+
+	var delay time.Duration
+	if updateConfig.Delay != "" {
+		var err error
+		if delay, err = time.ParseDuration(updateConfig.Delay); err != nil {
+			return fmt.Errorf("Invalid update_config delay %q: %v", updateConfig.Delay, err)
+		}
+	}
+
+	toRecreate := []*Container{}
+	for _, c := range containers {
+		outOfSync, err := c.OutOfSyncContext(ctx, imageName)
+		if err != nil {
+			return err
+		}
+		if forceRecreate || outOfSync {
+			toRecreate = append(toRecreate, c)
+		}
+	}
+	sort.Sort(byContainerNumber(toRecreate))
+
+	batchSize := updateConfig.Parallelism
+	if batchSize <= 0 {
+		batchSize = len(toRecreate)
+	}
+
+	failures := []error{}
+	for start := 0; start < len(toRecreate); start += batchSize {
+		if start > 0 && delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		end := start + batchSize
+		if end > len(toRecreate) {
+			end = len(toRecreate)
+		}
+
+		logrus.Infof("Recreating %s (%d/%d)", s.name, end, len(toRecreate))
+		tasks := utils.NewInParallel(s.context.ContainerParallelism)
+		for _, c := range toRecreate[start:end] {
+			task := func(c *Container) func() error {
+				return func() error {
+					if err := s.recreateOne(ctx, imageName, c, updateConfig.Order); err != nil {
+						return &project.ServiceError{
+							Service:   s.name,
+							Container: c.Name(),
+							Operation: "recreate",
+							Err:       err,
+						}
+					}
+					return nil
+				}
+			}(c)
+			tasks.Add(task)
+		}
+
+		if err := tasks.Wait(); err != nil {
+			failures = append(failures, tasks.Errors()...)
+			if updateConfig.FailureAction != UpdateFailureActionContinue {
+				logrus.Errorf("Update of %s paused after a failure", s.name)
+				return project.NewMultiError(failures...)
+			}
+		}
+	}
+
+	if len(failures) != 0 {
+		return project.NewMultiError(failures...)
+	}
+	return nil
+}
+
+func (s *Service) recreateOne(ctx context.Context, imageName string, c *Container, order string) error {
+	switch order {
+	case UpdateOrderStartFirst:
+		_, err := c.recreate(ctx, imageName, func(newContainer *types.ContainerJSON) error {
+			if err := c.StartContext(ctx, newContainer); err != nil {
+				return err
+			}
+			return c.waitReady(ctx, newContainer.ID)
+		})
+		return err
+	case "", UpdateOrderStopFirst:
+		newContainer, err := c.RecreateContext(ctx, imageName)
+		if err != nil {
+			return err
+		}
+		if err := c.StartContext(ctx, newContainer); err != nil {
+			return err
+		}
+		return c.waitReady(ctx, newContainer.ID)
+	default:
+		return fmt.Errorf("Invalid update_config order %q", order)
+	}
+}
+
+// waitReady waits for the specified container to be running and, if it has a
+// healthcheck, to be healthy.
+func (c *Container) waitReady(ctx context.Context, id string) error {
+	for {
+		info, err := c.client.ContainerInspect(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !info.State.Running {
+			return fmt.Errorf("Container %s is not running (exit code %d)", c.name, info.State.ExitCode)
+		}
+		if info.State.Health == nil || info.State.Health.Status == "healthy" {
+			return nil
+		}
+		if info.State.Health.Status == "unhealthy" {
+			return fmt.Errorf("Container %s is unhealthy", c.name)
+		}
+
+		select {
+		case <-time.After(readyPollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+type byContainerNumber []*Container
+
+func (c byContainerNumber) Len() int           { return len(c) }
+func (c byContainerNumber) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c byContainerNumber) Less(i, j int) bool { return c[i].containerNumber < c[j].containerNumber }
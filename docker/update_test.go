@@ -0,0 +1,83 @@
+package docker
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/docker/engine-api/types"
+	"github.com/hyperhq/libcompose/test"
+	"golang.org/x/net/context"
+)
+
+type ReadyClient struct {
+	test.NopClient
+	states []*types.ContainerState
+}
+
+func (client *ReadyClient) ContainerInspect(ctx context.Context, container string) (types.ContainerJSON, error) {
+	state := client.states[0]
+	if len(client.states) > 1 {
+		client.states = client.states[1:]
+	}
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:    container,
+			State: state,
+		},
+	}, nil
+}
+
+func TestWaitReady(t *testing.T) {
+	defer func(interval time.Duration) { readyPollInterval = interval }(readyPollInterval)
+	readyPollInterval = time.Millisecond
+
+	cases := []struct {
+		states []*types.ContainerState
+		ok     bool
+	}{
+		{
+			states: []*types.ContainerState{{Running: true}},
+			ok:     true,
+		},
+		{
+			states: []*types.ContainerState{
+				{Running: true, Health: &types.Health{Status: "starting"}},
+				{Running: true, Health: &types.Health{Status: "healthy"}},
+			},
+			ok: true,
+		},
+		{
+			states: []*types.ContainerState{
+				{Running: true, Health: &types.Health{Status: "starting"}},
+				{Running: true, Health: &types.Health{Status: "unhealthy"}},
+			},
+			ok: false,
+		},
+		{
+			states: []*types.ContainerState{{Running: false, ExitCode: 1}},
+			ok:     false,
+		},
+	}
+
+	for _, c := range cases {
+		container := &Container{name: "web_1", client: &ReadyClient{states: c.states}}
+		err := container.waitReady(context.Background(), "id")
+		if c.ok && err != nil {
+			t.Fatalf("expected the container to be ready, got %v", err)
+		}
+		if !c.ok && err == nil {
+			t.Fatalf("expected an error for states %v", c.states)
+		}
+	}
+}
+
+func TestByContainerNumber(t *testing.T) {
+	containers := []*Container{{containerNumber: 3}, {containerNumber: 1}, {containerNumber: 2}}
+	sort.Sort(byContainerNumber(containers))
+	for i, c := range containers {
+		if c.containerNumber != i+1 {
+			t.Fatalf("expected container %d at position %d, got %d", i+1, i, c.containerNumber)
+		}
+	}
+}
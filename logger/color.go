@@ -1,9 +1,7 @@
 package logger
 
-import "fmt"
-
 var (
-	colorPrefix = make(chan string)
+	colors = make(chan string)
 )
 
 func generateColors() {
@@ -24,7 +22,7 @@ func generateColors() {
 	}
 
 	for {
-		colorPrefix <- fmt.Sprintf("\033[%sm%%s |\033[0m", colorOrder[i])
+		colors <- colorOrder[i]
 		i = (i + 1) % len(colorOrder)
 	}
 }
@@ -1,75 +1,185 @@
 package logger
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
-	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"golang.org/x/crypto/ssh/terminal"
 )
 
+// DefaultPrefixTemplate is the template of the prefix written before every
+// line by a ColorLogger.
+const DefaultPrefixTemplate = "{{.Name}} |"
+
+// ColorLoggerOptions holds the options of a ColorLoggerFactory.
+type ColorLoggerOptions struct {
+	// NoColor disables the colored prefixes, even on a terminal.
+	NoColor bool
+	// Timestamps writes the time each line was received after the prefix.
+	Timestamps bool
+	// PrefixTemplate is a text/template of the prefix, executed with a
+	// Prefix. It defaults to DefaultPrefixTemplate.
+	PrefixTemplate string
+	// Out and Err are the writers of the standard output and error of the
+	// containers. They default to os.Stdout and os.Stderr.
+	Out io.Writer
+	Err io.Writer
+}
+
+// Prefix holds the fields available in a prefix template.
+type Prefix struct {
+	// Name is the logger name, padded to the length of the longest one.
+	Name      string
+	Service   string
+	Number    int
+	Timestamp time.Time
+}
+
 // ColorLoggerFactory implements logger.Factory interface using ColorLogger.
+// The loggers it creates share the output writers, and write whole lines so
+// the output of the containers does not interleave. It is safe for concurrent
+// use.
 type ColorLoggerFactory struct {
-	maxLength int
-	tty       bool
+	mu         sync.Mutex
+	maxLength  int
+	color      bool
+	timestamps bool
+	prefix     *template.Template
+	out        io.Writer
+	err        io.Writer
+	now        func() time.Time
 }
 
-// ColorLogger implements logger.Logger interface with color support.
+// ColorLogger implements logger.Logger interface with color support. Every
+// line is prefixed, partial lines are buffered until they are complete, or
+// until the logger is closed.
 type ColorLogger struct {
-	name        string
-	colorPrefix string
-	factory     *ColorLoggerFactory
+	name    string
+	service string
+	number  int
+	color   string
+	factory *ColorLoggerFactory
+	out     *lineWriter
+	err     *lineWriter
 }
 
-// NewColorLoggerFactory creates a new ColorLoggerFactory.
+// NewColorLoggerFactory creates a new ColorLoggerFactory writing to os.Stdout
+// and os.Stderr, with colors if os.Stdout is a terminal.
 func NewColorLoggerFactory() *ColorLoggerFactory {
-	return &ColorLoggerFactory{
-		tty: terminal.IsTerminal(int(os.Stdout.Fd())),
+	factory, _ := NewColorLoggerFactoryWithOptions(ColorLoggerOptions{})
+	return factory
+}
+
+// NewColorLoggerFactoryWithOptions creates a new ColorLoggerFactory with the
+// specified options. It returns an error if the prefix template is invalid.
+func NewColorLoggerFactoryWithOptions(options ColorLoggerOptions) (*ColorLoggerFactory, error) {
+	text := options.PrefixTemplate
+	if text == "" {
+		text = DefaultPrefixTemplate
+	}
+	prefix, err := template.New("prefix").Parse(text)
+	if err == nil {
+		err = prefix.Execute(ioutil.Discard, Prefix{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Invalid log prefix template %q: %v", text, err)
+	}
+
+	factory := &ColorLoggerFactory{
+		timestamps: options.Timestamps,
+		prefix:     prefix,
+		out:        options.Out,
+		err:        options.Err,
+		now:        time.Now,
+	}
+	if factory.out == nil {
+		factory.out = os.Stdout
 	}
+	if factory.err == nil {
+		factory.err = os.Stderr
+	}
+	if file, ok := factory.out.(*os.File); ok && !options.NoColor {
+		factory.color = terminal.IsTerminal(int(file.Fd()))
+	}
+	return factory, nil
 }
 
 // Create implements logger.Factory.Create.
 func (c *ColorLoggerFactory) Create(name string) Logger {
+	c.mu.Lock()
 	if c.maxLength < len(name) {
 		c.maxLength = len(name)
 	}
+	c.mu.Unlock()
 
-	return &ColorLogger{
-		name:        name,
-		factory:     c,
-		colorPrefix: <-colorPrefix,
+	service, number := splitLoggerName(name)
+	l := &ColorLogger{
+		name:    name,
+		service: service,
+		number:  number,
+		factory: c,
+	}
+	if c.color {
+		l.color = <-colors
 	}
+	l.out = newLineWriter(func(line []byte) { l.write(c.out, line) })
+	l.err = newLineWriter(func(line []byte) { l.write(c.err, line) })
+	return l
 }
 
 // Out implements logger.Logger.Out.
 func (c *ColorLogger) Out(bytes []byte) {
-	if len(bytes) == 0 {
-		return
-	}
-	logFmt, name := c.getLogFmt()
-	message := fmt.Sprintf(logFmt, name, string(bytes))
-	fmt.Print(message)
+	c.out.Write(bytes)
 }
 
 // Err implements logger.Logger.Err.
 func (c *ColorLogger) Err(bytes []byte) {
-	if len(bytes) == 0 {
-		return
-	}
-	logFmt, name := c.getLogFmt()
-	message := fmt.Sprintf(logFmt, name, string(bytes))
-	fmt.Fprint(os.Stderr, message)
+	c.err.Write(bytes)
 }
 
-func (c *ColorLogger) getLogFmt() (string, string) {
-	pad := c.factory.maxLength
+// Close writes the partial lines left in the buffers.
+func (c *ColorLogger) Close() error {
+	c.out.Close()
+	return c.err.Close()
+}
 
-	logFmt := "%s | %s"
-	if c.factory.tty {
-		logFmt = c.colorPrefix + " %s"
-	}
+func (c *ColorLogger) write(out io.Writer, line []byte) {
+	f := c.factory
+	now := f.now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
 
-	name := fmt.Sprintf("%-"+strconv.Itoa(pad)+"s", c.name)
+	prefix := &bytes.Buffer{}
+	if err := f.prefix.Execute(prefix, Prefix{
+		Name:      c.name + strings.Repeat(" ", f.maxLength-len(c.name)),
+		Service:   c.service,
+		Number:    c.number,
+		Timestamp: now,
+	}); err != nil {
+		prefix.Reset()
+		prefix.WriteString(c.name + " |")
+	}
 
-	return logFmt, name
+	message := &bytes.Buffer{}
+	if c.color != "" {
+		fmt.Fprintf(message, "\033[%sm%s\033[0m", c.color, prefix)
+	} else {
+		message.Write(prefix.Bytes())
+	}
+	message.WriteByte(' ')
+	if f.timestamps {
+		message.WriteString(now.UTC().Format(time.RFC3339Nano))
+		message.WriteByte(' ')
+	}
+	message.Write(line)
+	message.WriteByte('\n')
+	out.Write(message.Bytes())
 }
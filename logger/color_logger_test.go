@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorLogger(t *testing.T) {
+	out, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	factory, err := NewColorLoggerFactoryWithOptions(ColorLoggerOptions{
+		Out: out,
+		Err: stderr,
+	})
+	assert.NoError(t, err)
+
+	web := factory.Create("web-1")
+	db := factory.Create("db-10")
+	web.Out([]byte("first\nsec"))
+	db.Out([]byte("partial"))
+	web.Out([]byte("ond\n"))
+	db.Err([]byte("error\r\n"))
+	web.(*ColorLogger).Close()
+	db.(*ColorLogger).Close()
+
+	assert.Equal(t, "web-1 | first\nweb-1 | second\ndb-10 | partial\n", out.String())
+	assert.Equal(t, "db-10 | error\n", stderr.String())
+}
+
+func TestColorLoggerOptions(t *testing.T) {
+	now := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+	out := &bytes.Buffer{}
+	factory, err := NewColorLoggerFactoryWithOptions(ColorLoggerOptions{
+		Timestamps:     true,
+		PrefixTemplate: "[{{.Service}}#{{.Number}}]",
+		Out:            out,
+		Err:            out,
+	})
+	assert.NoError(t, err)
+	factory.now = func() time.Time { return now }
+
+	factory.Create("web-2").Out([]byte("message\n"))
+	assert.Equal(t, "[web#2] 2017-01-02T03:04:05Z message\n", out.String())
+
+	_, err = NewColorLoggerFactoryWithOptions(ColorLoggerOptions{PrefixTemplate: "{{.Unknown}}"})
+	assert.Error(t, err)
+}
+
+func TestColorLoggerConcurrent(t *testing.T) {
+	out := &bytes.Buffer{}
+	factory, err := NewColorLoggerFactoryWithOptions(ColorLoggerOptions{
+		Out: out,
+		Err: out,
+	})
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l := factory.Create(fmt.Sprintf("service-%d", i))
+			for j := 0; j < 100; j++ {
+				l.Out([]byte("hello "))
+				l.Out([]byte("world\n"))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Len(t, lines, 500)
+	for _, line := range lines {
+		assert.Regexp(t, `^service-\d \| hello world$`, line)
+	}
+}
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JSONLoggerFactory implements logger.Factory interface using JSONLogger. All
+// the loggers it creates write to the same io.Writer, one JSON object per line.
+type JSONLoggerFactory struct {
+	project string
+	mu      sync.Mutex
+	out     io.Writer
+	now     func() time.Time
+}
+
+// JSONLogger implements logger.Logger interface, writing every line of the
+// container output as a JSONLine. Partial lines are buffered until they are
+// complete, or until the logger is closed.
+type JSONLogger struct {
+	service string
+	number  int
+	factory *JSONLoggerFactory
+	out     *lineWriter
+	err     *lineWriter
+}
+
+// JSONLine is a line of container output, as written by a JSONLogger.
+type JSONLine struct {
+	Project         string    `json:"project"`
+	Service         string    `json:"service"`
+	ContainerNumber int       `json:"container_number,omitempty"`
+	Stream          string    `json:"stream"`
+	Timestamp       time.Time `json:"timestamp"`
+	Message         string    `json:"message"`
+}
+
+// NewJSONLoggerFactory creates a new JSONLoggerFactory writing the logs of the
+// specified project to the specified writer.
+func NewJSONLoggerFactory(project string, out io.Writer) *JSONLoggerFactory {
+	return &JSONLoggerFactory{
+		project: project,
+		out:     out,
+		now:     time.Now,
+	}
+}
+
+// Create implements logger.Factory.Create. The name is expected to be the
+// service name followed by the container number, like service-1.
+func (f *JSONLoggerFactory) Create(name string) Logger {
+	service, number := splitLoggerName(name)
+	l := &JSONLogger{
+		service: service,
+		number:  number,
+		factory: f,
+	}
+	l.out = newLineWriter(func(line []byte) { l.emit("stdout", line) })
+	l.err = newLineWriter(func(line []byte) { l.emit("stderr", line) })
+	return l
+}
+
+// Out implements logger.Logger.Out.
+func (l *JSONLogger) Out(p []byte) {
+	l.out.Write(p)
+}
+
+// Err implements logger.Logger.Err.
+func (l *JSONLogger) Err(p []byte) {
+	l.err.Write(p)
+}
+
+// Close writes the partial lines left in the buffers.
+func (l *JSONLogger) Close() error {
+	l.out.Close()
+	return l.err.Close()
+}
+
+func (l *JSONLogger) emit(stream string, message []byte) {
+	f := l.factory
+	line := JSONLine{
+		Project:         f.project,
+		Service:         l.service,
+		ContainerNumber: l.number,
+		Stream:          stream,
+		Timestamp:       f.now().UTC(),
+		Message:         string(message),
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.out.Write(append(data, '\n'))
+}
+
+// splitLoggerName splits a logger name like service-1 into the service name
+// and the container number. The number is 0 if the name does not end with one.
+func splitLoggerName(name string) (string, int) {
+	i := strings.LastIndex(name, "-")
+	if i < 0 {
+		return name, 0
+	}
+	number, err := strconv.Atoi(name[i+1:])
+	if err != nil || number <= 0 {
+		return name, 0
+	}
+	return name[:i], number
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	factory := NewJSONLoggerFactory("project", out)
+	now := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+	factory.now = func() time.Time { return now }
+
+	l := factory.Create("my-service-2")
+	l.Out([]byte("first line\nsecond "))
+	l.Err([]byte("error\r\n"))
+	l.Out([]byte("line\npartial"))
+	l.(*JSONLogger).Close()
+
+	lines := []JSONLine{}
+	for _, data := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		var line JSONLine
+		assert.NoError(t, json.Unmarshal([]byte(data), &line))
+		lines = append(lines, line)
+	}
+
+	expected := []JSONLine{
+		{Stream: "stdout", Message: "first line"},
+		{Stream: "stderr", Message: "error"},
+		{Stream: "stdout", Message: "second line"},
+		{Stream: "stdout", Message: "partial"},
+	}
+	assert.Len(t, lines, len(expected))
+	for i, line := range lines {
+		expected[i].Project = "project"
+		expected[i].Service = "my-service"
+		expected[i].ContainerNumber = 2
+		expected[i].Timestamp = now
+		assert.Equal(t, expected[i], line)
+	}
+}
+
+func TestSplitLoggerName(t *testing.T) {
+	cases := []struct {
+		name    string
+		service string
+		number  int
+	}{
+		{"web-1", "web", 1},
+		{"my-web-12", "my-web", 12},
+		{"web", "web", 0},
+		{"my-web", "my-web", 0},
+	}
+	for _, c := range cases {
+		service, number := splitLoggerName(c.name)
+		assert.Equal(t, c.service, service, c.name)
+		assert.Equal(t, c.number, number, c.name)
+	}
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+)
+
+// lineWriter is an io.WriteCloser splitting what is written into lines. The
+// line function is called for each complete line, without its line ending.
+// Partial lines are kept until they are complete, or until the writer is
+// closed. It is safe for concurrent use.
+type lineWriter struct {
+	mu     sync.Mutex
+	buffer bytes.Buffer
+	line   func(line []byte)
+}
+
+func newLineWriter(line func(line []byte)) *lineWriter {
+	return &lineWriter{line: line}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buffer.Write(p)
+	for {
+		i := bytes.IndexByte(w.buffer.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := w.buffer.Next(i + 1)
+		w.line(bytes.TrimSuffix(line[:i], []byte{'\r'}))
+	}
+}
+
+// Close calls the line function with the partial line left, if any.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buffer.Len() != 0 {
+		w.line(bytes.TrimSuffix(w.buffer.Bytes(), []byte{'\r'}))
+		w.buffer.Reset()
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package project
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/hyperhq/libcompose/project/options"
+	log "github.com/sirupsen/logrus"
+)
+
+// logFollower follows the logs of the containers of a project started while
+// the project is up in attached mode, including the ones started later by a
+// scale, and of the ones that were already running.
+type logFollower struct {
+	project *Project
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu        sync.Mutex
+	following map[string]bool
+	armed     bool
+	idle      chan struct{}
+	closed    bool
+}
+
+// attach makes the project follow the logs of the containers it starts, until
+// detach is called.
+func (p *Project) attach() *logFollower {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &logFollower{
+		project:   p,
+		ctx:       ctx,
+		cancel:    cancel,
+		following: map[string]bool{},
+		idle:      make(chan struct{}),
+	}
+
+	p.followerMu.Lock()
+	defer p.followerMu.Unlock()
+	p.follower = f
+	return f
+}
+
+// detach stops following the logs of the containers.
+func (p *Project) detach(f *logFollower) {
+	p.followerMu.Lock()
+	if p.follower == f {
+		p.follower = nil
+	}
+	p.followerMu.Unlock()
+	f.cancel()
+}
+
+// containerStarted follows the logs of the specified container if the
+// project is attached, and records its start if the project records them.
+func (p *Project) containerStarted(serviceName, name string) {
+	p.followerMu.Lock()
+	f := p.follower
+	starts := p.starts
+	p.followerMu.Unlock()
+	if f != nil {
+		f.follow(serviceName, name)
+	}
+	if starts != nil {
+		starts.add(name)
+	}
+}
+
+// waitAttached blocks until every followed container stopped, or until the
+// context is done, in which case the services are stopped.
+func (p *Project) waitAttached(ctx context.Context, f *logFollower, services ...string) error {
+	if err := p.followRunning(ctx, f, services...); err != nil {
+		return err
+	}
+	f.arm()
+	select {
+	case <-f.idle:
+		return nil
+	case <-ctx.Done():
+		log.Infof("Gracefully stopping project %s", p.Name)
+		// The services have to be stopped even though the operation was
+		// cancelled.
+		return p.StopContext(context.Background(), 10, services...)
+	}
+}
+
+// followRunning follows the logs of the containers of the specified services
+// that were already running, and so were not started by the project.
+func (p *Project) followRunning(ctx context.Context, f *logFollower, services ...string) error {
+	names, err := p.selectedServices(services)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		service, err := p.CreateService(name)
+		if err != nil {
+			return err
+		}
+
+		containers, err := service.ContainersContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, container := range containers {
+			running, err := container.IsRunningContext(ctx)
+			if err != nil {
+				return err
+			}
+			if running {
+				f.follow(name, container.Name())
+			}
+		}
+	}
+	return nil
+}
+
+func (f *logFollower) follow(serviceName, name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.following[name] {
+		return
+	}
+	f.following[name] = true
+
+	go func() {
+		if err := f.log(serviceName, name); err != nil && f.ctx.Err() == nil {
+			log.Errorf("Failed to follow the logs of %s: %v", name, err)
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.following, name)
+		f.checkIdle()
+	}()
+}
+
+func (f *logFollower) log(serviceName, name string) error {
+	service, err := f.project.CreateService(serviceName)
+	if err != nil {
+		return err
+	}
+
+	containers, err := service.ContainersContext(f.ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, container := range containers {
+		if container.Name() == name {
+			return container.LogContext(f.ctx, options.Log{Follow: true})
+		}
+	}
+	return nil
+}
+
+// arm makes idle be closed as soon as no container is followed anymore.
+func (f *logFollower) arm() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.armed = true
+	f.checkIdle()
+}
+
+func (f *logFollower) checkIdle() {
+	if f.armed && !f.closed && len(f.following) == 0 {
+		f.closed = true
+		close(f.idle)
+	}
+}
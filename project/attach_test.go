@@ -0,0 +1,67 @@
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project/options"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestUpAttached(t *testing.T) {
+	web := &TestConditionContainer{name: "web_1"}
+	job := &TestConditionContainer{name: "job_1"}
+	factory := &TestHookServiceFactory{
+		containers: map[string][]Container{
+			"web": {web},
+			"job": {job},
+		},
+	}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{})
+	p.ServiceConfigs.Add("job", &config.ServiceConfig{})
+
+	if err := p.UpContext(context.Background(), options.Up{Attached: true}); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, web.logged)
+	assert.True(t, job.logged)
+	assert.Empty(t, factory.called("stop"))
+
+	web.running = true
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.UpContext(ctx, options.Up{Attached: true}); err != nil {
+		t.Fatal(err)
+	}
+	assert.ElementsMatch(t, []string{"web", "job"}, factory.called("stop"))
+}
+
+func TestUpAttachedRunning(t *testing.T) {
+	web := &TestConditionContainer{name: "web_1", running: true}
+	factory := &TestHookServiceFactory{
+		containers: map[string][]Container{"web": {web}},
+		upToDate:   map[string]bool{"web": true},
+	}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.UpContext(ctx, options.Up{Attached: true}); err != nil {
+		t.Fatal(err)
+	}
+	// The running container was followed, so Up blocked until the context
+	// was done and stopped the service.
+	assert.Equal(t, []string{"web"}, factory.called("stop"))
+}
@@ -0,0 +1,91 @@
+package project
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/hyperhq/libcompose/project/options"
+)
+
+// The operations below predate the context.Context argument, they run the
+// corresponding Context operation with context.Background().
+
+// Build builds the specified services (like docker build).
+func (p *Project) Build(buildOptions options.Build, services ...string) error {
+	return p.BuildContext(context.Background(), buildOptions, services...)
+}
+
+// Create creates the specified services (like docker create).
+func (p *Project) Create(options options.Create, services ...string) error {
+	return p.CreateContext(context.Background(), options, services...)
+}
+
+// Delete removes the specified services (like docker rm).
+func (p *Project) Delete(options options.Delete, services ...string) error {
+	return p.DeleteContext(context.Background(), options, services...)
+}
+
+// Down stops the specified services and clean related containers (like docker stop + docker rm).
+func (p *Project) Down(opts options.Down, services ...string) error {
+	return p.DownContext(context.Background(), opts, services...)
+}
+
+// Kill kills the specified services (like docker kill).
+func (p *Project) Kill(signal string, services ...string) error {
+	return p.KillContext(context.Background(), signal, services...)
+}
+
+// Log aggregates and prints out the logs for the specified services.
+func (p *Project) Log(follow bool, services ...string) error {
+	return p.LogContext(context.Background(), options.Log{Follow: follow}, services...)
+}
+
+// Pause pauses the specified services containers (like docker pause).
+func (p *Project) Pause(services ...string) error {
+	return p.PauseContext(context.Background(), services...)
+}
+
+// Ps list containers for the specified services.
+func (p *Project) Ps(onlyID bool, services ...string) (InfoSet, error) {
+	return p.PsContext(context.Background(), onlyID, services...)
+}
+
+// Port returns the public port for a port binding of the container with the
+// specified number of the specified service.
+func (p *Project) Port(number int, protocol, serviceName, privatePort string) (string, error) {
+	return p.PortContext(context.Background(), number, protocol, serviceName, privatePort)
+}
+
+// Pull pulls the specified services (like docker pull).
+func (p *Project) Pull(services ...string) error {
+	return p.PullContext(context.Background(), services...)
+}
+
+// Restart restarts the specified services (like docker restart).
+func (p *Project) Restart(timeout int, services ...string) error {
+	return p.RestartContext(context.Background(), timeout, services...)
+}
+
+// Scale scales the specified services.
+func (p *Project) Scale(timeout int, servicesScale map[string]int) error {
+	return p.ScaleContext(context.Background(), timeout, servicesScale)
+}
+
+// Start starts the specified services (like docker start).
+func (p *Project) Start(services ...string) error {
+	return p.StartContext(context.Background(), services...)
+}
+
+// Stop stops the specified services (like docker stop).
+func (p *Project) Stop(timeout int, services ...string) error {
+	return p.StopContext(context.Background(), timeout, services...)
+}
+
+// Unpause pauses the specified services containers (like docker pause).
+func (p *Project) Unpause(services ...string) error {
+	return p.UnpauseContext(context.Background(), services...)
+}
+
+// Up creates and starts the specified services (kinda like docker run).
+func (p *Project) Up(options options.Up, services ...string) error {
+	return p.UpContext(context.Background(), options, services...)
+}
@@ -0,0 +1,73 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	yaml "github.com/cloudfoundry-incubator/candiedyaml"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project/options"
+)
+
+// Config renders the configuration of the project as it is once parsed, as a
+// v2 document: the compose files merged, the services extended, their
+// env_file read and their environment interpolated and looked up. The output
+// is YAML or JSON, depending on options.Format. If options.Services or
+// options.Volumes is set, only the name of the services or of the volumes is
+// listed, one per line.
+func (p *Project) Config(options options.Config) (string, error) {
+	switch {
+	case options.Services:
+		return nameList(p.ServiceConfigs.Keys()), nil
+	case options.Volumes:
+		volumes := make([]string, 0, len(p.VolumeConfigs))
+		for name := range p.VolumeConfigs {
+			volumes = append(volumes, name)
+		}
+		return nameList(volumes), nil
+	}
+
+	resolved := config.ResolvedConfig{
+		Version:  "2",
+		Services: map[string]*config.ServiceConfig{},
+		Volumes:  p.VolumeConfigs,
+		Networks: p.NetworkConfigs,
+	}
+	for _, name := range p.ServiceConfigs.Keys() {
+		serviceConfig, err := p.resolveServiceConfig(name)
+		if err != nil {
+			return "", err
+		}
+		// Already resolved
+		serviceConfig.Extends = nil
+		resolved.Services[name] = serviceConfig
+	}
+
+	switch options.Format {
+	case "", "yaml":
+		out, err := yaml.Marshal(resolved)
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
+	case "json":
+		out, err := json.MarshalIndent(resolved, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(out) + "\n", nil
+	default:
+		return "", fmt.Errorf("Invalid config format %q", options.Format)
+	}
+}
+
+func nameList(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return strings.Join(names, "\n") + "\n"
+}
@@ -0,0 +1,71 @@
+package project
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudfoundry-incubator/candiedyaml"
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project/options"
+	"github.com/hyperhq/libcompose/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig(t *testing.T) {
+	p := NewProject(nil, &Context{
+		EnvironmentLookup: &TestEnvironmentLookup{},
+	})
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{
+		Image:       "nginx:1.11",
+		Environment: yaml.MaporEqualSlice{"DEBUG"},
+		DependsOn:   yaml.DependsOn{{Service: "db", Condition: "service_healthy"}},
+		Extends:     yaml.MaporEqualSlice{"service=base"},
+	})
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{
+		Image: "postgres",
+	})
+	p.VolumeConfigs["data"] = &config.VolumeConfig{}
+	p.VolumeConfigs["logs"] = &config.VolumeConfig{Driver: "local"}
+
+	out, err := p.Config(options.Config{Services: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "db\nweb\n", out)
+
+	out, err = p.Config(options.Config{Volumes: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "data\nlogs\n", out)
+
+	out, err = p.Config(options.Config{Format: "json"})
+	assert.Nil(t, err)
+	var document struct {
+		Version  string
+		Services map[string]map[string]interface{}
+		Volumes  map[string]map[string]interface{}
+	}
+	if err := json.Unmarshal([]byte(out), &document); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "2", document.Version)
+	assert.Equal(t, "nginx:1.11", document.Services["web"]["image"])
+	assert.Equal(t, []interface{}{"DEBUG=X"}, document.Services["web"]["environment"])
+	assert.Equal(t, map[string]interface{}{"db": map[string]interface{}{"condition": "service_healthy"}}, document.Services["web"]["depends_on"])
+	assert.Nil(t, document.Services["web"]["extends"])
+	assert.Equal(t, "postgres", document.Services["db"]["image"])
+	assert.Equal(t, "local", document.Volumes["logs"]["driver"])
+
+	out, err = p.Config(options.Config{})
+	assert.Nil(t, err)
+	resolved := config.ResolvedConfig{}
+	if err := candiedyaml.Unmarshal([]byte(out), &resolved); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "2", resolved.Version)
+	assert.Equal(t, "nginx:1.11", resolved.Services["web"].Image)
+	assert.Equal(t, yaml.MaporEqualSlice{"DEBUG=X"}, resolved.Services["web"].Environment)
+	assert.Equal(t, yaml.DependsOn{{Service: "db", Condition: "service_healthy"}}, resolved.Services["web"].DependsOn)
+	assert.Equal(t, "local", resolved.Volumes["logs"].Driver)
+
+	if _, err := p.Config(options.Config{Format: "toml"}); err == nil {
+		t.Fatal("expected an error for an invalid format")
+	}
+}
@@ -1,9 +1,40 @@
 package project
 
-// Container defines what a libcompose container provides.
+import (
+	"golang.org/x/net/context"
+
+	"github.com/hyperhq/libcompose/project/options"
+)
+
+// Container defines what a libcompose container provides. The operations
+// taking a context.Context that predate it are suffixed with Context.
 type Container interface {
-	ID() (string, error)
+	IDContext(ctx context.Context) (string, error)
 	Name() string
+	PortContext(ctx context.Context, port string) (string, error)
+	IsRunningContext(ctx context.Context) (bool, error)
+	// Health returns the health status of the container, or an empty string
+	// if the container has no healthcheck.
+	Health(ctx context.Context) (string, error)
+	// Wait blocks until the container stops and returns its exit code.
+	Wait(ctx context.Context) (int, error)
+	// Status returns the status of the container, or nil if the container
+	// does not exist.
+	Status(ctx context.Context) (*ContainerStatus, error)
+	// Top returns the processes running in the container, or nil if the
+	// container is not running.
+	Top(ctx context.Context) (*ContainerProcesses, error)
+	// Stats streams samples of the resource usage of the container until the
+	// context is done or the engine stops reporting them. It returns nil if
+	// the container is not running.
+	Stats(ctx context.Context) (<-chan ContainerStats, error)
+	// LogContext writes the logs of the container to the logger of the
+	// project.
+	LogContext(ctx context.Context, options options.Log) error
+
+	// The operations below predate the context.Context argument, they use
+	// context.Background().
+	ID() (string, error)
 	Port(port string) (string, error)
 	IsRunning() (bool, error)
 }
@@ -31,6 +31,14 @@ type Context struct {
 	Project             *Project
 
 	Autoremove bool
+
+	// Parallelism is the maximum number of services an operation works on at
+	// the same time, ContainerParallelism the maximum number of containers of a
+	// service. Zero means no limit.
+	Parallelism          int
+	ContainerParallelism int
+	// FailFast cancels the services still pending as soon as one service fails.
+	FailFast bool
 }
 
 func (c *Context) readComposeFiles() error {
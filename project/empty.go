@@ -1,6 +1,12 @@
 package project
 
 import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"golang.org/x/net/context"
+
 	"github.com/hyperhq/libcompose/project/options"
 )
 
@@ -8,6 +14,121 @@ import (
 type EmptyService struct {
 }
 
+// CreateContext implements Service.CreateContext but does nothing.
+func (e *EmptyService) CreateContext(ctx context.Context, options options.Create) error {
+	return nil
+}
+
+// BuildContext implements Service.BuildContext but does nothing.
+func (e *EmptyService) BuildContext(ctx context.Context, buildOptions options.Build) error {
+	return nil
+}
+
+// UpContext implements Service.UpContext but does nothing.
+func (e *EmptyService) UpContext(ctx context.Context, options options.Up) error {
+	return nil
+}
+
+// StartContext implements Service.StartContext but does nothing.
+func (e *EmptyService) StartContext(ctx context.Context) error {
+	return nil
+}
+
+// StopContext implements Service.StopContext() but does nothing.
+func (e *EmptyService) StopContext(ctx context.Context, timeout int) error {
+	return nil
+}
+
+// DeleteContext implements Service.DeleteContext but does nothing.
+func (e *EmptyService) DeleteContext(ctx context.Context, options options.Delete) error {
+	return nil
+}
+
+// RestartContext implements Service.RestartContext but does nothing.
+func (e *EmptyService) RestartContext(ctx context.Context, timeout int) error {
+	return nil
+}
+
+// LogContext implements Service.LogContext but does nothing.
+func (e *EmptyService) LogContext(ctx context.Context, options options.Log) error {
+	return nil
+}
+
+// PullContext implements Service.PullContext but does nothing.
+func (e *EmptyService) PullContext(ctx context.Context) error {
+	return nil
+}
+
+// KillContext implements Service.KillContext but does nothing.
+func (e *EmptyService) KillContext(ctx context.Context, signal string) error {
+	return nil
+}
+
+// ContainersContext implements Service.ContainersContext but does nothing.
+func (e *EmptyService) ContainersContext(ctx context.Context) ([]Container, error) {
+	return []Container{}, nil
+}
+
+// ScaleContext implements Service.ScaleContext but does nothing.
+func (e *EmptyService) ScaleContext(ctx context.Context, count int, timeout int) error {
+	return nil
+}
+
+// InfoContext implements Service.InfoContext but does nothing.
+func (e *EmptyService) InfoContext(ctx context.Context, qFlag bool) (InfoSet, error) {
+	return InfoSet{}, nil
+}
+
+// PauseContext implements Service.PauseContext but does nothing.
+func (e *EmptyService) PauseContext(ctx context.Context) error {
+	return nil
+}
+
+// UnpauseContext implements Service.PauseContext but does nothing.
+func (e *EmptyService) UnpauseContext(ctx context.Context) error {
+	return nil
+}
+
+// Run implements Service.Run but does nothing.
+func (e *EmptyService) Run(ctx context.Context, commandParts []string) (int, error) {
+	return 0, nil
+}
+
+// RunWithOptions implements Service.RunWithOptions but does nothing.
+func (e *EmptyService) RunWithOptions(ctx context.Context, commandParts []string, options options.Run) (int, error) {
+	return 0, nil
+}
+
+// Exec implements Service.Exec but does nothing.
+func (e *EmptyService) Exec(ctx context.Context, number int, commandParts []string, options options.Exec) (int, error) {
+	return 0, nil
+}
+
+// CopyTo implements Service.CopyTo but does nothing.
+func (e *EmptyService) CopyTo(ctx context.Context, number int, path string, content io.Reader) error {
+	return nil
+}
+
+// CopyFrom implements Service.CopyFrom but returns an empty stream.
+func (e *EmptyService) CopyFrom(ctx context.Context, number int, path string) (io.ReadCloser, error) {
+	return ioutil.NopCloser(&bytes.Buffer{}), nil
+}
+
+// PlanUp implements Service.PlanUp but does nothing.
+func (e *EmptyService) PlanUp(ctx context.Context, options options.Up) ([]Action, error) {
+	return []Action{}, nil
+}
+
+// PlanScale implements Service.PlanScale but does nothing.
+func (e *EmptyService) PlanScale(ctx context.Context, count int) ([]Action, error) {
+	return []Action{}, nil
+}
+
+// RemoveImageContext implements Service.RemoveImageContext but does nothing.
+func (e *EmptyService) RemoveImageContext(ctx context.Context, imageType options.ImageType) error {
+	return nil
+}
+
 // Create implements Service.Create but does nothing.
 func (e *EmptyService) Create(options options.Create) error {
 	return nil
@@ -83,11 +204,6 @@ func (e *EmptyService) Unpause() error {
 	return nil
 }
 
-// Run implements Service.Run but does nothing.
-func (e *EmptyService) Run(commandParts []string) (int, error) {
-	return 0, nil
-}
-
 // RemoveImage implements Service.RemoveImage but does nothing.
 func (e *EmptyService) RemoveImage(imageType options.ImageType) error {
 	return nil
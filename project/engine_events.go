@@ -0,0 +1,126 @@
+package project
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/types"
+	eventtypes "github.com/docker/engine-api/types/events"
+	"github.com/docker/engine-api/types/filters"
+	"github.com/hyperhq/libcompose/labels"
+	"github.com/hyperhq/libcompose/project/events"
+	log "github.com/sirupsen/logrus"
+)
+
+// engineEvents maps the container actions reported by the engine to the
+// libcompose events.
+var engineEvents = map[string]events.EventType{
+	"start":         events.ContainerStarted,
+	"die":           events.ContainerDied,
+	"oom":           events.ContainerOOM,
+	"health_status": events.ContainerHealth,
+	"destroy":       events.ContainerRemoved,
+}
+
+// Events subscribes to the event stream of the engine and returns the events
+// about the containers of the project (start, die, oom, health_status and
+// destroy). Each event carries the service name, and the container id, name
+// and number in its data, plus the exit code or the health status when the
+// engine reports them. The channel is closed when the context is done or when
+// the engine closes the stream.
+func (p *Project) Events(ctx context.Context) (<-chan events.Event, error) {
+	filter := filters.NewArgs()
+	filter.Add("type", eventtypes.ContainerEventType)
+	for key, values := range labels.PROJECT.Eq(p.Name) {
+		for _, value := range values {
+			filter.Add(key, value)
+		}
+	}
+
+	body, err := p.clientFactory.Create(nil).Events(ctx, types.EventsOptions{
+		Filters: filter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan events.Event)
+	go func() {
+		defer close(out)
+		defer body.Close()
+
+		decoder := json.NewDecoder(body)
+		for {
+			var message eventtypes.Message
+			if err := decoder.Decode(&message); err != nil {
+				if err != io.EOF && ctx.Err() == nil {
+					log.Errorf("Failed to decode engine event: %v", err)
+				}
+				return
+			}
+
+			event, ok := p.engineEvent(message)
+			if !ok {
+				continue
+			}
+
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// engineEvent converts an event of the engine to a libcompose event. It
+// returns false if the event is not about a container of the project or is
+// not one of engineEvents.
+func (p *Project) engineEvent(message eventtypes.Message) (events.Event, bool) {
+	if message.Type != "" && message.Type != eventtypes.ContainerEventType {
+		return events.Event{}, false
+	}
+
+	attributes := message.Actor.Attributes
+	if attributes[labels.PROJECT.Str()] != p.Name {
+		return events.Event{}, false
+	}
+
+	action := message.Action
+	if action == "" {
+		action = message.Status
+	}
+
+	data := map[string]string{}
+	// Health events are reported as "health_status: healthy"
+	if parts := strings.SplitN(action, ":", 2); len(parts) == 2 {
+		action = parts[0]
+		data["health"] = strings.TrimSpace(parts[1])
+	}
+
+	eventType, ok := engineEvents[action]
+	if !ok {
+		return events.Event{}, false
+	}
+
+	data["id"] = message.Actor.ID
+	if data["id"] == "" {
+		data["id"] = message.ID
+	}
+	data["name"] = attributes["name"]
+	data["number"] = attributes[labels.NUMBER.Str()]
+	if exitCode, ok := attributes["exitCode"]; ok {
+		data["exit_code"] = exitCode
+	}
+
+	return events.Event{
+		EventType:   eventType,
+		ServiceName: attributes[labels.SERVICE.Str()],
+		Data:        data,
+	}, true
+}
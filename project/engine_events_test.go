@@ -0,0 +1,66 @@
+package project
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/docker/engine-api/client"
+	"github.com/docker/engine-api/types"
+	"github.com/hyperhq/libcompose/labels"
+	"github.com/hyperhq/libcompose/project/events"
+	"github.com/hyperhq/libcompose/test"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+type TestEventsClient struct {
+	test.NopClient
+	body string
+}
+
+func (c *TestEventsClient) Events(ctx context.Context, options types.EventsOptions) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader(c.body)), nil
+}
+
+type TestEventsClientFactory struct {
+	client *TestEventsClient
+}
+
+func (f *TestEventsClientFactory) Create(service Service) client.APIClient {
+	return f.client
+}
+
+func TestEvents(t *testing.T) {
+	attributes := func(project, service, number string) string {
+		return fmt.Sprintf(`"name":"%s_%s_%s","%s":"%s","%s":"%s","%s":"%s"`,
+			project, service, number, labels.PROJECT, project, labels.SERVICE, service, labels.NUMBER, number)
+	}
+	body := strings.Join([]string{
+		`{"Type":"container","Action":"start","Actor":{"ID":"1","Attributes":{` + attributes("app", "web", "1") + `}}}`,
+		`{"Type":"container","Action":"exec_start: ls","Actor":{"ID":"1","Attributes":{` + attributes("app", "web", "1") + `}}}`,
+		`{"Type":"container","Action":"health_status: unhealthy","Actor":{"ID":"2","Attributes":{` + attributes("app", "db", "2") + `}}}`,
+		`{"Type":"container","Action":"die","Actor":{"ID":"3","Attributes":{` + attributes("other", "web", "1") + `}}}`,
+		`{"Type":"container","Action":"die","Actor":{"ID":"2","Attributes":{"exitCode":"137",` + attributes("app", "db", "2") + `}}}`,
+	}, "\n")
+
+	p := NewProject(&TestEventsClientFactory{client: &TestEventsClient{body: body}}, &Context{})
+	p.Name = "app"
+
+	stream, err := p.Events(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := []events.Event{}
+	for event := range stream {
+		received = append(received, event)
+	}
+
+	assert.Equal(t, []events.Event{
+		{EventType: events.ContainerStarted, ServiceName: "web", Data: map[string]string{"id": "1", "name": "app_web_1", "number": "1"}},
+		{EventType: events.ContainerHealth, ServiceName: "db", Data: map[string]string{"id": "2", "name": "app_db_2", "number": "2", "health": "unhealthy"}},
+		{EventType: events.ContainerDied, ServiceName: "db", Data: map[string]string{"id": "2", "name": "app_db_2", "number": "2", "exit_code": "137"}},
+	}, received)
+}
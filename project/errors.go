@@ -0,0 +1,163 @@
+package project
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// ServiceError records the failure of an operation on a service, or on one of
+// its containers.
+type ServiceError struct {
+	Service   string
+	Container string
+	Operation string
+	Err       error
+}
+
+func (e *ServiceError) Error() string {
+	target := e.Service
+	if e.Container != "" {
+		target = fmt.Sprintf("%s (container %s)", e.Service, e.Container)
+	}
+	switch {
+	case e.Operation != "" && target != "":
+		return fmt.Sprintf("Failed to %s %s: %v", e.Operation, target, e.Err)
+	case e.Operation != "":
+		return fmt.Sprintf("Failed to %s: %v", e.Operation, e.Err)
+	case target != "":
+		return fmt.Sprintf("%s: %v", target, e.Err)
+	default:
+		return e.Err.Error()
+	}
+}
+
+// Unwrap returns the underlying error.
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError holds every failure of a project or service operation, sorted by
+// service and container name. The failures are listed in Errors.
+//
+// The operations of a project on a set of services, like Up or Scale, return a
+// *MultiError whenever services fail, even if a single one did, so the
+// failures can be inspected the same way for every operation. The operations
+// on a single service (Run, Exec, CopyTo, CopyFrom and Port) return the error
+// of the service as is, as does every operation for the errors which are not the
+// failure of a service, like an unknown service or invalid options.
+type MultiError struct {
+	Errors []*ServiceError
+}
+
+// NewMultiError returns a MultiError holding the specified errors. Errors that
+// are not a *ServiceError are recorded without service. It returns nil if
+// there is no error.
+func NewMultiError(errs ...error) *MultiError {
+	m := &MultiError{}
+	for _, err := range errs {
+		m.add(err, "", "")
+	}
+	if len(m.Errors) == 0 {
+		return nil
+	}
+	return m
+}
+
+// add records the specified error for the specified service and operation,
+// flattening the *MultiError and keeping the *ServiceError as they are.
+func (e *MultiError) add(err error, service, operation string) {
+	switch err := err.(type) {
+	case nil:
+	case *MultiError:
+		for _, serviceErr := range err.Errors {
+			e.add(serviceErr, service, operation)
+		}
+	case *ServiceError:
+		// The error is copied, as it may be held by the caller.
+		serviceErr := *err
+		if serviceErr.Service == "" {
+			serviceErr.Service = service
+		}
+		if serviceErr.Operation == "" {
+			serviceErr.Operation = operation
+		}
+		e.Errors = append(e.Errors, &serviceErr)
+	default:
+		e.Errors = append(e.Errors, &ServiceError{
+			Service:   service,
+			Operation: operation,
+			Err:       err,
+		})
+	}
+	sort.Stable(byServiceAndContainer(e.Errors))
+}
+
+// errorOrNil returns nil if no error was recorded, the MultiError otherwise.
+func (e *MultiError) errorOrNil() error {
+	if e == nil || len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, "\t* "+err.Error())
+	}
+	return fmt.Sprintf("%d errors occurred:\n%s", len(e.Errors), strings.Join(messages, "\n"))
+}
+
+// dropCanceled removes the errors of the services that were cancelled, unless
+// every service was cancelled, in which case the operation itself was.
+func (e *MultiError) dropCanceled() {
+	errs := []*ServiceError{}
+	for _, err := range e.Errors {
+		if err.Err != context.Canceled {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		e.Errors = errs
+	}
+}
+
+// Services returns the name of the services that failed, sorted.
+func (e *MultiError) Services() []string {
+	services := []string{}
+	for _, err := range e.Errors {
+		if err.Service != "" && (len(services) == 0 || services[len(services)-1] != err.Service) {
+			services = append(services, err.Service)
+		}
+	}
+	return services
+}
+
+// ExitError records the non-zero exit code of a container, when an Up aborts
+// on container exit.
+type ExitError struct {
+	Service  string
+	Name     string
+	ExitCode int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("Container %s of %s exited with code %d", e.Name, e.Service, e.ExitCode)
+}
+
+type byServiceAndContainer []*ServiceError
+
+func (s byServiceAndContainer) Len() int      { return len(s) }
+func (s byServiceAndContainer) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byServiceAndContainer) Less(i, j int) bool {
+	if s[i].Service != s[j].Service {
+		return s[i].Service < s[j].Service
+	}
+	return s[i].Container < s[j].Container
+}
@@ -0,0 +1,66 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project/options"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestMultiError(t *testing.T) {
+	factory := &TestHookServiceFactory{hooks: failing("db")}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{})
+
+	err := p.CreateContext(context.Background(), options.Create{})
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("expected a MultiError, got %v", err)
+	}
+	assert.Equal(t, "db", multiErr.Errors[0].Service)
+	assert.Equal(t, "create", multiErr.Errors[0].Operation)
+	assert.Equal(t, "Failed to create db: db failed", err.Error())
+
+	err = p.ScaleContext(context.Background(), 10, map[string]int{"db": 3})
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("expected a MultiError, got %v", err)
+	}
+	var serviceErr *ServiceError
+	if !errors.As(multiErr.Errors[0], &serviceErr) || serviceErr.Service != "db" {
+		t.Fatalf("expected a ServiceError of db, got %v", err)
+	}
+	assert.Equal(t, "Failed to set the scale 3 of db: db failed", err.Error())
+
+	_, err = p.Run(context.Background(), "db", []string{"ls"})
+	var runErr *MultiError
+	if errors.As(err, &runErr) {
+		t.Fatalf("expected the error of the service, got %v", err)
+	}
+	assert.Equal(t, "db failed", err.Error())
+
+	stopErr := &ServiceError{Container: "web_1", Err: fmt.Errorf("timeout")}
+	multiErr = &MultiError{}
+	multiErr.add(stopErr, "web", "stop")
+	assert.Equal(t, &ServiceError{Container: "web_1", Err: fmt.Errorf("timeout")}, stopErr)
+	assert.Equal(t, "Failed to stop web (container web_1): timeout", multiErr.Error())
+
+	err = NewMultiError(
+		&ServiceError{Service: "web", Container: "web_2", Operation: "stop", Err: fmt.Errorf("timeout")},
+		&ServiceError{Service: "web", Container: "web_1", Operation: "stop", Err: fmt.Errorf("timeout")},
+		fmt.Errorf("boom"),
+	)
+	assert.Equal(t, `3 errors occurred:
+	* boom
+	* Failed to stop web (container web_1): timeout
+	* Failed to stop web (container web_2): timeout`, err.Error())
+	assert.Equal(t, []string{"web"}, err.(*MultiError).Services())
+}
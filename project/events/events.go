@@ -88,6 +88,12 @@ const (
 	ProjectUnpauseDone   = EventType(iota)
 	ProjectStopStart     = EventType(iota)
 	ProjectStopDone      = EventType(iota)
+
+	// Appended so the values of the events above do not change.
+	ContainerDied    = EventType(iota)
+	ContainerOOM     = EventType(iota)
+	ContainerHealth  = EventType(iota)
+	ContainerRemoved = EventType(iota)
 )
 
 func (e EventType) String() string {
@@ -97,6 +103,14 @@ func (e EventType) String() string {
 		m = "Created container"
 	case ContainerStarted:
 		m = "Started container"
+	case ContainerDied:
+		m = "Container died"
+	case ContainerOOM:
+		m = "Container ran out of memory"
+	case ContainerHealth:
+		m = "Container health changed"
+	case ContainerRemoved:
+		m = "Removed container"
 
 	case ServiceAdd:
 		m = "Adding"
@@ -19,3 +19,10 @@ func TestEventEquality(t *testing.T) {
 		t.Fatal("Events match")
 	}
 }
+
+func TestEventValues(t *testing.T) {
+	// The values are part of the API, new events must not renumber the others.
+	if ContainerStarted != 2 || ServiceAdd != 3 || ProjectStopDone != 56 {
+		t.Fatalf("Event values changed: %d %d %d", ContainerStarted, ServiceAdd, ProjectStopDone)
+	}
+}
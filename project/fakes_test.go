@@ -0,0 +1,240 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project/events"
+	"github.com/hyperhq/libcompose/project/options"
+	"golang.org/x/net/context"
+)
+
+// TestHookServiceFactory creates services which record the operations called
+// on them, run the hook of their service, if any, and return the containers
+// of their service.
+type TestHookServiceFactory struct {
+	sync.Mutex
+	project    *Project
+	containers map[string][]Container
+	// hooks holds, per service, a function run by every operation of the
+	// service, whose error is returned by the operation.
+	hooks map[string]func(ctx context.Context, operation string) error
+	// upToDate holds the services whose containers are already running, so
+	// Up does not start them.
+	upToDate map[string]bool
+	calls    []string
+}
+
+type TestHookService struct {
+	factory *TestHookServiceFactory
+	name    string
+	config  *config.ServiceConfig
+	EmptyService
+}
+
+func (t *TestHookService) Config() *config.ServiceConfig {
+	return t.config
+}
+
+func (t *TestHookService) Name() string {
+	return t.name
+}
+
+func (t *TestHookService) call(ctx context.Context, operation string) error {
+	t.factory.record(operation, t.name)
+	if hook := t.factory.hooks[t.name]; hook != nil {
+		return hook(ctx, operation)
+	}
+	return nil
+}
+
+func (t *TestHookService) CreateContext(ctx context.Context, options options.Create) error {
+	return t.call(ctx, "create")
+}
+
+func (t *TestHookService) UpContext(ctx context.Context, options options.Up) error {
+	if err := t.call(ctx, "up"); err != nil {
+		return err
+	}
+	if t.factory.project != nil && !t.factory.upToDate[t.name] {
+		containers, _ := t.ContainersContext(ctx)
+		for _, container := range containers {
+			t.factory.project.Notify(events.ContainerStarted, t.name, map[string]string{
+				"name": container.Name(),
+			})
+		}
+	}
+	return nil
+}
+
+func (t *TestHookService) StopContext(ctx context.Context, timeout int) error {
+	return t.call(ctx, "stop")
+}
+
+func (t *TestHookService) ScaleContext(ctx context.Context, count int, timeout int) error {
+	return t.call(ctx, "scale")
+}
+
+func (t *TestHookService) RunWithOptions(ctx context.Context, commandParts []string, options options.Run) (int, error) {
+	if err := t.call(ctx, "run"); err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
+
+func (t *TestHookService) PlanUp(ctx context.Context, options options.Up) ([]Action, error) {
+	return []Action{{Type: ActionCreate, Container: t.name + "_1"}}, nil
+}
+
+func (t *TestHookService) ContainersContext(ctx context.Context) ([]Container, error) {
+	t.factory.Lock()
+	defer t.factory.Unlock()
+	return t.factory.containers[t.name], nil
+}
+
+func (t *TestHookService) DependentServices() []ServiceRelationship {
+	return DefaultDependentServices(t.factory.project, t)
+}
+
+func (t *TestHookServiceFactory) Create(project *Project, name string, serviceConfig *config.ServiceConfig) (Service, error) {
+	return &TestHookService{
+		factory: t,
+		config:  serviceConfig,
+		name:    name,
+	}, nil
+}
+
+func (t *TestHookServiceFactory) record(operation, service string) {
+	t.Lock()
+	defer t.Unlock()
+	t.calls = append(t.calls, operation+" "+service)
+}
+
+// called returns the services the specified operation was called on, in the
+// order of the calls.
+func (t *TestHookServiceFactory) called(operation string) []string {
+	t.Lock()
+	defer t.Unlock()
+	services := []string{}
+	for _, call := range t.calls {
+		if strings.HasPrefix(call, operation+" ") {
+			services = append(services, strings.TrimPrefix(call, operation+" "))
+		}
+	}
+	return services
+}
+
+// failing returns hooks making the operations of the specified service fail.
+func failing(service string) map[string]func(ctx context.Context, operation string) error {
+	return map[string]func(ctx context.Context, operation string) error{
+		service: func(ctx context.Context, operation string) error {
+			return fmt.Errorf("%s failed", service)
+		},
+	}
+}
+
+type TestConditionContainer struct {
+	sync.Mutex
+	health    []string
+	exitCode  int
+	status    *ContainerStatus
+	processes *ContainerProcesses
+	stats     []ContainerStats
+	statsErr  error
+	statsCtx  context.Context
+	running   bool
+	exited    bool
+	waitErr   error
+	name      string
+	port      string
+	logged    bool
+}
+
+func (c *TestConditionContainer) IDContext(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+func (c *TestConditionContainer) Name() string {
+	if c.name != "" {
+		return c.name
+	}
+	return "test"
+}
+
+func (c *TestConditionContainer) PortContext(ctx context.Context, port string) (string, error) {
+	return c.port, nil
+}
+
+func (c *TestConditionContainer) IsRunningContext(ctx context.Context) (bool, error) {
+	return !c.exited, nil
+}
+
+func (c *TestConditionContainer) ID() (string, error) {
+	return c.IDContext(context.Background())
+}
+
+func (c *TestConditionContainer) Port(port string) (string, error) {
+	return c.PortContext(context.Background(), port)
+}
+
+func (c *TestConditionContainer) IsRunning() (bool, error) {
+	return c.IsRunningContext(context.Background())
+}
+
+func (c *TestConditionContainer) Health(ctx context.Context) (string, error) {
+	c.Lock()
+	defer c.Unlock()
+	health := c.health[0]
+	if len(c.health) > 1 {
+		c.health = c.health[1:]
+	}
+	return health, nil
+}
+
+func (c *TestConditionContainer) Wait(ctx context.Context) (int, error) {
+	if c.waitErr != nil {
+		return -1, c.waitErr
+	}
+	if c.running {
+		<-ctx.Done()
+		return -1, ctx.Err()
+	}
+	return c.exitCode, nil
+}
+
+func (c *TestConditionContainer) Status(ctx context.Context) (*ContainerStatus, error) {
+	return c.status, nil
+}
+
+func (c *TestConditionContainer) Top(ctx context.Context) (*ContainerProcesses, error) {
+	return c.processes, nil
+}
+
+func (c *TestConditionContainer) Stats(ctx context.Context) (<-chan ContainerStats, error) {
+	c.statsCtx = ctx
+	if c.statsErr != nil {
+		return nil, c.statsErr
+	}
+	if c.stats == nil {
+		return nil, nil
+	}
+	out := make(chan ContainerStats, len(c.stats))
+	for _, sample := range c.stats {
+		out <- sample
+	}
+	close(out)
+	return out, nil
+}
+
+func (c *TestConditionContainer) LogContext(ctx context.Context, options options.Log) error {
+	c.Lock()
+	c.logged = true
+	c.Unlock()
+	if c.running && options.Follow {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
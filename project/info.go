@@ -2,8 +2,10 @@ package project
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"text/tabwriter"
+	"text/template"
 )
 
 // InfoPart holds key/value strings.
@@ -51,3 +53,58 @@ func writeLine(writer io.Writer, key bool, info Info) {
 
 	writer.Write([]byte{'\n'})
 }
+
+// Map returns the parts of the info keyed by their key.
+func (info Info) Map() map[string]string {
+	result := make(map[string]string, len(info))
+	for _, part := range info {
+		result[part.Key] = part.Value
+	}
+	return result
+}
+
+// Format renders the infos as a table (format "" or "table"), as JSON (format
+// "json") or with the specified Go template, executed for each info with its
+// parts keyed by their key (e.g. "{{.Name}}").
+func (infos InfoSet) Format(format string) (string, error) {
+	items := make([]interface{}, 0, len(infos))
+	for _, info := range infos {
+		items = append(items, info.Map())
+	}
+
+	switch format {
+	case "", "table":
+		return infos.String(true), nil
+	case "json":
+		return formatJSON(items)
+	default:
+		return formatTemplate(format, items)
+	}
+}
+
+func formatJSON(v interface{}) (string, error) {
+	buffer := bytes.NewBuffer(make([]byte, 0, 1024))
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func formatTemplate(format string, items []interface{}) (string, error) {
+	tmpl, err := template.New("").Parse(format)
+	if err != nil {
+		return "", err
+	}
+
+	buffer := bytes.NewBuffer(make([]byte, 0, 1024))
+	for _, item := range items {
+		if err := tmpl.Execute(buffer, item); err != nil {
+			return "", err
+		}
+		buffer.WriteByte('\n')
+	}
+	return buffer.String(), nil
+}
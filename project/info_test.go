@@ -0,0 +1,21 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfoSetFormat(t *testing.T) {
+	infos := InfoSet{
+		{{Key: "Name", Value: "web_1"}, {Key: "State", Value: "Up"}},
+	}
+
+	output, err := infos.Format("{{.Name}}: {{.State}}")
+	assert.Nil(t, err)
+	assert.Equal(t, "web_1: Up\n", output)
+
+	output, err = infos.Format("json")
+	assert.Nil(t, err)
+	assert.Equal(t, "[\n  {\n    \"Name\": \"web_1\",\n    \"State\": \"Up\"\n  }\n]\n", output)
+}
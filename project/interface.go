@@ -1,6 +1,8 @@
 package project
 
 import (
+	"io"
+
 	"github.com/hyperhq/libcompose/config"
 	"github.com/hyperhq/libcompose/project/events"
 	"github.com/hyperhq/libcompose/project/options"
@@ -8,10 +10,45 @@ import (
 )
 
 // APIProject is an interface defining the methods a libcompose project should implement.
+// Every operation has a variant taking a context.Context, which is passed down to the
+// services, their containers and the engine API calls, so it can be cancelled or given
+// a deadline. The variants of the older operations are suffixed with Context.
+// The operations on a single container of a service (CopyFrom, CopyTo, Exec and
+// Port) address it by its container number, as in the name of the container.
 type APIProject interface {
 	events.Notifier
 	events.Emitter
 
+	BuildContext(ctx context.Context, options options.Build, sevice ...string) error
+	CopyFrom(ctx context.Context, number int, serviceName, path string) (io.ReadCloser, error)
+	CopyTo(ctx context.Context, number int, serviceName, path string, content io.Reader) error
+	CreateContext(ctx context.Context, options options.Create, services ...string) error
+	DeleteContext(ctx context.Context, options options.Delete, services ...string) error
+	DownContext(ctx context.Context, options options.Down, services ...string) error
+	Events(ctx context.Context) (<-chan events.Event, error)
+	Exec(ctx context.Context, serviceName string, number int, commandParts []string, options options.Exec) (int, error)
+	KillContext(ctx context.Context, signal string, services ...string) error
+	LogContext(ctx context.Context, options options.Log, services ...string) error
+	PauseContext(ctx context.Context, services ...string) error
+	PsContext(ctx context.Context, onlyID bool, services ...string) (InfoSet, error)
+	Status(ctx context.Context, options options.Ps, services ...string) (ContainerStatuses, error)
+	// FIXME(vdemeester) we could use nat.Port instead ?
+	PortContext(ctx context.Context, number int, protocol, serviceName, privatePort string) (string, error)
+	PullContext(ctx context.Context, services ...string) error
+	RestartContext(ctx context.Context, timeout int, services ...string) error
+	Run(ctx context.Context, serviceName string, commandParts []string) (int, error)
+	RunWithOptions(ctx context.Context, serviceName string, commandParts []string, options options.Run) (int, error)
+	ScaleContext(ctx context.Context, timeout int, servicesScale map[string]int) error
+	StartContext(ctx context.Context, services ...string) error
+	Stats(ctx context.Context, services ...string) (<-chan []ServiceStats, error)
+	StopContext(ctx context.Context, timeout int, services ...string) error
+	Top(ctx context.Context, services ...string) ([]ServiceProcesses, error)
+	UnpauseContext(ctx context.Context, services ...string) error
+	UpContext(ctx context.Context, options options.Up, services ...string) error
+	Wait(ctx context.Context, services ...string) (*ContainerExit, error)
+
+	// The operations below predate the context.Context argument, they use
+	// context.Background().
 	Build(options options.Build, sevice ...string) error
 	Create(options options.Create, services ...string) error
 	Delete(options options.Delete, services ...string) error
@@ -20,17 +57,20 @@ type APIProject interface {
 	Log(follow bool, services ...string) error
 	Pause(services ...string) error
 	Ps(onlyID bool, services ...string) (InfoSet, error)
-	// FIXME(vdemeester) we could use nat.Port instead ?
-	Port(index int, protocol, serviceName, privatePort string) (string, error)
+	Port(number int, protocol, serviceName, privatePort string) (string, error)
 	Pull(services ...string) error
 	Restart(timeout int, services ...string) error
-	Run(ctx context.Context, serviceName string, commandParts []string) (int, error)
 	Scale(timeout int, servicesScale map[string]int) error
 	Start(services ...string) error
 	Stop(timeout int, services ...string) error
 	Unpause(services ...string) error
 	Up(options options.Up, services ...string) error
 
+	PlanDown(ctx context.Context, options options.Down, services ...string) (*Plan, error)
+	PlanScale(ctx context.Context, timeout int, servicesScale map[string]int) (*Plan, error)
+	PlanUp(ctx context.Context, options options.Up, services ...string) (*Plan, error)
+
 	Parse() error
+	Config(options options.Config) (string, error)
 	GetConfig() (*config.ServiceConfigs, map[string]*config.VolumeConfig, map[string]*config.NetworkConfig)
 }
@@ -1,5 +1,7 @@
 package options
 
+import "io"
+
 // Build holds options of compose build.
 type Build struct {
 	NoCache     bool
@@ -31,6 +33,117 @@ type Create struct {
 // Up holds options of compose up.
 type Up struct {
 	Create
+	// Transactional keeps the replaced containers until every service is up,
+	// and rolls back all the services touched if one of them fails.
+	Transactional bool
+	// AbortOnContainerExit waits for a container to exit once the services
+	// are up, then stops them all.
+	AbortOnContainerExit bool
+	// ExitCodeFrom waits for a container of this service to exit once the
+	// services are up, then stops them all, and returns its exit code. It
+	// implies AbortOnContainerExit.
+	ExitCodeFrom string
+	// Attached follows the logs of the containers as soon as they start, and
+	// blocks until they stop, or stops the services when the operation is
+	// cancelled.
+	Attached bool
+	// Timeout is the timeout, in seconds, to stop the containers removed to
+	// match the scale key of a service. It defaults to 10 seconds if zero.
+	Timeout int
+}
+
+// Ps holds options of compose ps.
+type Ps struct {
+	// States only keeps the containers in one of these states (e.g. running,
+	// exited, paused). All the containers are kept if empty.
+	States []string
+}
+
+// Log holds options of compose logs.
+type Log struct {
+	Follow bool
+	// Since and Until only keep the logs after, or before, this time. It is
+	// either absolute, as a RFC 3339 date or a Unix timestamp, or relative
+	// to now, as a duration like "10m".
+	Since string
+	Until string
+	// Tail is the number of lines to show from the end of the logs, or "all"
+	// (the default).
+	Tail       string
+	Timestamps bool
+	// Number only shows the logs of the container with this number. The logs
+	// of every container are shown if 0.
+	Number int
+}
+
+// Run holds options of compose run. They only apply to the one-off container,
+// never to the service configuration.
+type Run struct {
+	// Detach starts the container in the background, and returns as soon as
+	// it is started, without attaching to it.
+	Detach bool
+	// NoDeps does not create the services the service depends on.
+	NoDeps bool
+	// Env adds environment variables, as KEY=value, to the ones of the
+	// service.
+	Env        []string
+	User       string
+	WorkingDir string
+	Entrypoint []string
+	// Ports publishes ports of the container, like 8080:80. The ports of
+	// the service are not published.
+	Ports []string
+	// Name is the name of the container, instead of the generated one.
+	Name string
+	// DisableTty runs the command without a terminal, keeping its standard
+	// output and error apart, like in a CI job.
+	DisableTty bool
+	// Interactive forwards the standard input to the command when it runs
+	// without a terminal. It is always forwarded with a terminal.
+	Interactive bool
+	// Stdin, Stdout and Stderr are the streams of the command. They default
+	// to os.Stdin, os.Stdout and os.Stderr.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// ProxySignals forwards the signals received by the process, like
+	// SIGINT or SIGTERM, to the container.
+	ProxySignals bool
+	// DetachKeys overrides the key sequence to detach from the container,
+	// like ctrl-p,ctrl-q.
+	DetachKeys string
+}
+
+// Exec holds options of compose exec.
+type Exec struct {
+	// Detach runs the command in the background, without attaching to it.
+	Detach bool
+	Tty    bool
+	// Interactive attaches the standard input to the command.
+	Interactive bool
+	User        string
+	// Stdin, Stdout and Stderr are the streams of the command. They default
+	// to os.Stdin, os.Stdout and os.Stderr.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// ProxySignals forwards the signals received by the process, like
+	// SIGINT or SIGTERM, to the container. The engine API cannot signal the
+	// command itself, so they reach the main process of the container.
+	ProxySignals bool
+	// DetachKeys overrides the key sequence to detach from the command,
+	// like ctrl-p,ctrl-q.
+	DetachKeys string
+}
+
+// Config holds options of compose config.
+type Config struct {
+	// Format is the output format, "yaml" (the default) or "json".
+	Format string
+	// Services only lists the name of the services, one per line.
+	Services bool
+	// Volumes only lists the name of the volumes, one per line.
+	Volumes bool
 }
 
 // ImageType defines the type of image (local, all)
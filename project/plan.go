@@ -0,0 +1,254 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/net/context"
+
+	"github.com/hyperhq/libcompose/project/options"
+	"github.com/hyperhq/libcompose/utils"
+)
+
+// ActionType defines the kind of change a plan would make to a container.
+type ActionType string
+
+// Action types
+const (
+	ActionCreate   = ActionType("create")
+	ActionRecreate = ActionType("recreate")
+	ActionStart    = ActionType("start")
+	ActionStop     = ActionType("stop")
+	ActionRemove   = ActionType("remove")
+)
+
+// Action describes a single change a plan would make to a container.
+type Action struct {
+	Type      ActionType
+	Container string
+	Reason    string
+}
+
+// ServicePlan holds the actions a plan would perform for a service.
+type ServicePlan struct {
+	Service string
+	Actions []Action
+}
+
+// Plan holds what an operation (Up, Down or Scale) would do to the project,
+// without anything being executed. Services are sorted so that dependencies
+// come before their dependents.
+type Plan struct {
+	Services []ServicePlan
+	Orphans  []Action
+
+	apply func(ctx context.Context) error
+}
+
+// Empty returns whether the plan has nothing to do.
+func (p *Plan) Empty() bool {
+	if len(p.Orphans) != 0 {
+		return false
+	}
+	for _, service := range p.Services {
+		if len(service.Actions) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply executes the operation the plan was computed for. The operation is
+// run against the current state of the project, which may have changed since
+// the plan was made.
+func (p *Plan) Apply(ctx context.Context) error {
+	if p.apply == nil {
+		return fmt.Errorf("Plan cannot be applied")
+	}
+	return p.apply(ctx)
+}
+
+// String returns a table of the actions of the plan, one per line.
+func (p *Plan) String() string {
+	buffer := bytes.NewBuffer(make([]byte, 0, 1024))
+	tabwriter := tabwriter.NewWriter(buffer, 4, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tabwriter, "SERVICE\tACTION\tCONTAINER\tREASON")
+	for _, service := range p.Services {
+		for _, action := range service.Actions {
+			writeAction(tabwriter, service.Service, action)
+		}
+	}
+	for _, action := range p.Orphans {
+		writeAction(tabwriter, "(orphan)", action)
+	}
+
+	tabwriter.Flush()
+	return buffer.String()
+}
+
+func writeAction(tabwriter *tabwriter.Writer, service string, action Action) {
+	fmt.Fprintln(tabwriter, strings.Join([]string{service, string(action.Type), action.Container, action.Reason}, "\t"))
+}
+
+// PlanUp returns what Up would do for the specified services.
+func (p *Project) PlanUp(ctx context.Context, options options.Up, services ...string) (*Plan, error) {
+	if options.NoRecreate && options.ForceRecreate {
+		return nil, fmt.Errorf("no-recreate and force-recreate cannot be combined")
+	}
+	plan, err := p.planServices(services, func(service Service) ([]Action, error) {
+		return service.PlanUp(ctx, options)
+	})
+	if err != nil {
+		return nil, err
+	}
+	plan.apply = func(ctx context.Context) error {
+		return p.UpContext(ctx, options, services...)
+	}
+	return plan, nil
+}
+
+// PlanDown returns what Down would do for the specified services. Image
+// removal is not part of the plan.
+func (p *Project) PlanDown(ctx context.Context, opts options.Down, services ...string) (*Plan, error) {
+	if !opts.RemoveImages.Valid() {
+		return nil, fmt.Errorf("--rmi flag must be local, all or empty")
+	}
+	plan, err := p.planServices(services, func(service Service) ([]Action, error) {
+		containers, err := service.ContainersContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		actions := []Action{}
+		for _, container := range containers {
+			running, err := container.IsRunningContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if running {
+				actions = append(actions, Action{Type: ActionStop, Container: container.Name(), Reason: "container is running"})
+			}
+			actions = append(actions, Action{Type: ActionRemove, Container: container.Name(), Reason: "service is going down"})
+		}
+		return actions, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.RemoveOrphans {
+		orphans, err := p.listOrphanContainers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, orphan := range orphans {
+			plan.Orphans = append(plan.Orphans, Action{Type: ActionRemove, Container: containerName(orphan.Names), Reason: "service is not defined in the project"})
+		}
+	}
+	plan.apply = func(ctx context.Context) error {
+		return p.DownContext(ctx, opts, services...)
+	}
+	return plan, nil
+}
+
+// PlanScale returns what Scale would do for the specified services.
+func (p *Project) PlanScale(ctx context.Context, timeout int, servicesScale map[string]int) (*Plan, error) {
+	services := make([]string, 0, len(servicesScale))
+	for name := range servicesScale {
+		if !p.ServiceConfigs.Has(name) {
+			return nil, fmt.Errorf("%s is not defined in the template", name)
+		}
+		services = append(services, name)
+	}
+	plan, err := p.planServices(services, func(service Service) ([]Action, error) {
+		return service.PlanScale(ctx, servicesScale[service.Name()])
+	})
+	if err != nil {
+		return nil, err
+	}
+	plan.apply = func(ctx context.Context) error {
+		return p.ScaleContext(ctx, timeout, servicesScale)
+	}
+	return plan, nil
+}
+
+func (p *Project) planServices(services []string, planService func(service Service) ([]Action, error)) (*Plan, error) {
+	order, err := p.serviceOrder(services)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{}
+	for _, service := range order {
+		actions, err := planService(service)
+		if err != nil {
+			return nil, err
+		}
+		plan.Services = append(plan.Services, ServicePlan{
+			Service: service.Name(),
+			Actions: actions,
+		})
+	}
+	return plan, nil
+}
+
+// serviceOrder returns the specified services, or all the services of the
+// project if none is specified, sorted so that dependencies come before their
+// dependents.
+func (p *Project) serviceOrder(services []string) ([]Service, error) {
+	names := services
+	if len(names) == 0 {
+		names = p.ServiceConfigs.Keys()
+	}
+
+	selected := map[string]bool{}
+	for _, name := range names {
+		if !p.ServiceConfigs.Has(name) {
+			return nil, fmt.Errorf("No such service: %s", name)
+		}
+		selected[name] = true
+	}
+
+	order := []Service{}
+	visited := map[string]bool{}
+	var visit func(name string, history []string) error
+	visit = func(name string, history []string) error {
+		if visited[name] {
+			return nil
+		}
+		visited[name] = true
+		history = append(history, name)
+
+		service, err := p.CreateService(name)
+		if err != nil {
+			return err
+		}
+		for _, dep := range service.DependentServices() {
+			if !p.ServiceConfigs.Has(dep.Target) {
+				continue
+			}
+			if utils.Contains(history, dep.Target) {
+				if dep.Optional {
+					continue
+				}
+				return fmt.Errorf("Cycle detected in path %s", strings.Join(append(history, dep.Target), "->"))
+			}
+			if err := visit(dep.Target, history); err != nil {
+				return err
+			}
+		}
+
+		if selected[name] {
+			order = append(order, service)
+		}
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, []string{}); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,57 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project/options"
+	"github.com/hyperhq/libcompose/yaml"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestPlanUp(t *testing.T) {
+	factory := &TestHookServiceFactory{}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "db"}}})
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+
+	plan, err := p.PlanUp(context.Background(), options.Up{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []ServicePlan{
+		{Service: "db", Actions: []Action{{Type: ActionCreate, Container: "db_1"}}},
+		{Service: "web", Actions: []Action{{Type: ActionCreate, Container: "web_1"}}},
+	}, plan.Services)
+	assert.Empty(t, factory.calls, "planning should not execute anything")
+
+	if err := plan.Apply(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"db", "web"}, factory.called("up"))
+}
+
+func TestPlanDown(t *testing.T) {
+	p, factory := newConditionProject("", &TestConditionContainer{})
+
+	plan, err := p.PlanDown(context.Background(), options.Down{}, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []ServicePlan{
+		{Service: "db", Actions: []Action{
+			{Type: ActionStop, Container: "test", Reason: "container is running"},
+			{Type: ActionRemove, Container: "test", Reason: "service is going down"},
+		}},
+	}, plan.Services)
+	assert.Empty(t, factory.calls)
+
+	if _, err := p.PlanDown(context.Background(), options.Down{}, "unknown"); err == nil {
+		t.Fatal("expected an error for an unknown service")
+	}
+}
@@ -3,7 +3,9 @@ package project
 import (
 	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"sync"
 
 	"golang.org/x/net/context"
 
@@ -18,7 +20,7 @@ import (
 	"github.com/hyperhq/libcompose/utils"
 )
 
-type wrapperAction func(*serviceWrapper, map[string]*serviceWrapper)
+type wrapperAction func(context.Context, *serviceWrapper, map[string]*serviceWrapper)
 type serviceAction func(service Service) error
 
 // Project holds libcompose project information.
@@ -36,6 +38,11 @@ type Project struct {
 	upCount       int
 	listeners     []chan<- events.Event
 	hasListeners  bool
+	slots         chan struct{}
+	slotsOnce     sync.Once
+	follower      *logFollower
+	followerMu    sync.Mutex
+	starts        *startRecord
 }
 
 // NewProject creates a new project with the specified context.
@@ -97,6 +104,17 @@ func (p *Project) Parse() error {
 // CreateService creates a service with the specified name based. If there
 // is no config in the project for this service, it will return an error.
 func (p *Project) CreateService(name string) (Service, error) {
+	config, err := p.resolveServiceConfig(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.context.ServiceFactory.Create(p, name, config)
+}
+
+// resolveServiceConfig returns a copy of the config of the specified service
+// with its environment looked up.
+func (p *Project) resolveServiceConfig(name string) (*config.ServiceConfig, error) {
 	existing, ok := p.ServiceConfigs.Get(name)
 	if !ok {
 		return nil, fmt.Errorf("Failed to find service: %s", name)
@@ -125,7 +143,7 @@ func (p *Project) CreateService(name string) (Service, error) {
 		config.Environment = parsedEnv
 	}
 
-	return p.context.ServiceFactory.Create(p, name, &config)
+	return &config, nil
 }
 
 // AddConfig adds the specified service config for the specified name.
@@ -202,132 +220,199 @@ func (p *Project) loadWrappers(wrappers map[string]*serviceWrapper, servicesToCo
 	return nil
 }
 
-// Build builds the specified services (like docker build).
-func (p *Project) Build(buildOptions options.Build, services ...string) error {
-	return p.perform(events.ProjectBuildStart, events.ProjectBuildDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(wrappers, events.ServiceBuildStart, events.ServiceBuild, func(service Service) error {
-			return service.Build(buildOptions)
+// BuildContext builds the specified services (like docker build).
+func (p *Project) BuildContext(ctx context.Context, buildOptions options.Build, services ...string) error {
+	return p.perform(ctx, events.ProjectBuildStart, events.ProjectBuildDone, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, wrappers, events.ServiceBuildStart, events.ServiceBuild, func(service Service) error {
+			return service.BuildContext(ctx, buildOptions)
 		})
 	}), nil)
 }
 
-// Create creates the specified services (like docker create).
-func (p *Project) Create(options options.Create, services ...string) error {
+// CreateContext creates the specified services (like docker create).
+func (p *Project) CreateContext(ctx context.Context, options options.Create, services ...string) error {
 	if options.NoRecreate && options.ForceRecreate {
 		return fmt.Errorf("no-recreate and force-recreate cannot be combined")
 	}
-	return p.perform(events.ProjectCreateStart, events.ProjectCreateDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(wrappers, events.ServiceCreateStart, events.ServiceCreate, func(service Service) error {
-			return service.Create(options)
+	return p.perform(ctx, events.ProjectCreateStart, events.ProjectCreateDone, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, wrappers, events.ServiceCreateStart, events.ServiceCreate, func(service Service) error {
+			return service.CreateContext(ctx, options)
 		})
 	}), nil)
 }
 
-// Stop stops the specified services (like docker stop).
-func (p *Project) Stop(timeout int, services ...string) error {
-	return p.perform(events.ProjectStopStart, events.ProjectStopDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(nil, events.ServiceStopStart, events.ServiceStop, func(service Service) error {
-			return service.Stop(timeout)
+// StopContext stops the specified services (like docker stop).
+func (p *Project) StopContext(ctx context.Context, timeout int, services ...string) error {
+	return p.perform(ctx, events.ProjectStopStart, events.ProjectStopDone, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, nil, events.ServiceStopStart, events.ServiceStop, func(service Service) error {
+			return service.StopContext(ctx, timeout)
 		})
 	}), nil)
 }
 
-// Down stops the specified services and clean related containers (like docker stop + docker rm).
-func (p *Project) Down(opts options.Down, services ...string) error {
+// DownContext stops the specified services and clean related containers (like docker stop + docker rm).
+func (p *Project) DownContext(ctx context.Context, opts options.Down, services ...string) error {
 	if !opts.RemoveImages.Valid() {
 		return fmt.Errorf("--rmi flag must be local, all or empty")
 	}
-	if err := p.Stop(10, services...); err != nil {
+	if err := p.StopContext(ctx, 10, services...); err != nil {
 		return err
 	}
 	if opts.RemoveOrphans {
-		if err := p.removeOrphanContainers(); err != nil {
+		if err := p.removeOrphanContainers(ctx); err != nil {
 			return err
 		}
 	}
-	if err := p.Delete(options.Delete{
+	if err := p.DeleteContext(ctx, options.Delete{
 		RemoveVolume: opts.RemoveVolume,
 	}, services...); err != nil {
 		return err
 	}
 
-	return p.forEach([]string{}, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(wrappers, events.NoEvent, events.NoEvent, func(service Service) error {
-			return service.RemoveImage(opts.RemoveImages)
+	return p.forEach(ctx, []string{}, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, wrappers, events.NoEvent, events.NoEvent, func(service Service) error {
+			return service.RemoveImageContext(ctx, opts.RemoveImages)
 		})
 	}), func(service Service) error {
-		return service.Create(options.Create{})
+		return service.CreateContext(ctx, options.Create{})
 	})
 }
 
-func (p *Project) removeOrphanContainers() error {
+func (p *Project) removeOrphanContainers(ctx context.Context) error {
+	client := p.clientFactory.Create(nil)
+	containers, err := p.listOrphanContainers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, container := range containers {
+		if err := client.ContainerKill(ctx, container.ID, "SIGKILL"); err != nil {
+			return err
+		}
+		if _, err := client.ContainerRemove(ctx, container.ID, types.ContainerRemoveOptions{
+			Force: true,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listOrphanContainers lists the containers of the project whose service is
+// not defined anymore.
+func (p *Project) listOrphanContainers(ctx context.Context) ([]types.Container, error) {
 	client := p.clientFactory.Create(nil)
 	filter := filters.NewArgs()
 	filter.Add("label", labels.PROJECT.EqString(p.Name))
-	containers, err := client.ContainerList(context.Background(), types.ContainerListOptions{
+	containers, err := client.ContainerList(ctx, types.ContainerListOptions{
 		Filter: filter,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	currentServices := map[string]struct{}{}
 	for _, serviceName := range p.ServiceConfigs.Keys() {
 		currentServices[serviceName] = struct{}{}
 	}
+	orphans := []types.Container{}
 	for _, container := range containers {
 		serviceLabel := container.Labels[labels.SERVICE.Str()]
 		if _, ok := currentServices[serviceLabel]; !ok {
-			if err := client.ContainerKill(context.Background(), container.ID, "SIGKILL"); err != nil {
-				return err
-			}
-			if _, err := client.ContainerRemove(context.Background(), container.ID, types.ContainerRemoveOptions{
-				Force: true,
-			}); err != nil {
-				return err
-			}
+			orphans = append(orphans, container)
 		}
 	}
-	return nil
+	return orphans, nil
+}
+
+func containerName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(names[0], "/")
 }
 
-// Restart restarts the specified services (like docker restart).
-func (p *Project) Restart(timeout int, services ...string) error {
-	return p.perform(events.ProjectRestartStart, events.ProjectRestartDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(wrappers, events.ServiceRestartStart, events.ServiceRestart, func(service Service) error {
-			return service.Restart(timeout)
+// RestartContext restarts the specified services (like docker restart).
+func (p *Project) RestartContext(ctx context.Context, timeout int, services ...string) error {
+	return p.perform(ctx, events.ProjectRestartStart, events.ProjectRestartDone, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, wrappers, events.ServiceRestartStart, events.ServiceRestart, func(service Service) error {
+			return service.RestartContext(ctx, timeout)
 		})
 	}), nil)
 }
 
-// Port returns the public port for a port binding of the specified service.
-func (p *Project) Port(index int, protocol, serviceName, privatePort string) (string, error) {
+// PortContext returns the public port for a port binding of the container of the
+// specified service with the specified number.
+func (p *Project) PortContext(ctx context.Context, number int, protocol, serviceName, privatePort string) (string, error) {
 	service, err := p.CreateService(serviceName)
 	if err != nil {
 		return "", err
 	}
 
-	containers, err := service.Containers()
+	container, err := containerNumbered(ctx, service, number)
 	if err != nil {
 		return "", err
 	}
 
-	if index < 1 || index > len(containers) {
-		return "", fmt.Errorf("Invalid index %d", index)
+	return container.PortContext(ctx, fmt.Sprintf("%s/%s", privatePort, protocol))
+}
+
+// containerNumbered returns the container of the specified service with the
+// specified number.
+func containerNumbered(ctx context.Context, service Service, number int) (Container, error) {
+	containers, err := service.ContainersContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return containers[index-1].Port(fmt.Sprintf("%s/%s", privatePort, protocol))
+	for _, container := range containers {
+		status, err := container.Status(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if status != nil && status.Number == number {
+			return container, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Service %s has no container number %d", service.Name(), number)
+}
+
+// CopyTo copies the specified tar stream to the specified path in the
+// container of the specified service with the specified number.
+func (p *Project) CopyTo(ctx context.Context, number int, serviceName, path string, content io.Reader) error {
+	service, err := p.CreateService(serviceName)
+	if err != nil {
+		return err
+	}
+
+	return service.CopyTo(ctx, number, path, content)
 }
 
-// Ps list containers for the specified services.
-func (p *Project) Ps(onlyID bool, services ...string) (InfoSet, error) {
+// CopyFrom returns a tar stream of the specified path in the container of the
+// specified service with the specified number.
+func (p *Project) CopyFrom(ctx context.Context, number int, serviceName, path string) (io.ReadCloser, error) {
+	service, err := p.CreateService(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.CopyFrom(ctx, number, path)
+}
+
+// PsContext list containers for the specified services.
+func (p *Project) PsContext(ctx context.Context, onlyID bool, services ...string) (InfoSet, error) {
+	names, err := p.selectedServices(services)
+	if err != nil {
+		return nil, err
+	}
+
 	allInfo := InfoSet{}
-	for _, name := range p.ServiceConfigs.Keys() {
+	for _, name := range names {
 		service, err := p.CreateService(name)
 		if err != nil {
 			return nil, err
 		}
 
-		info, err := service.Info(onlyID)
+		info, err := service.InfoContext(ctx, onlyID)
 		if err != nil {
 			return nil, err
 		}
@@ -337,59 +422,204 @@ func (p *Project) Ps(onlyID bool, services ...string) (InfoSet, error) {
 	return allInfo, nil
 }
 
-// Start starts the specified services (like docker start).
-func (p *Project) Start(services ...string) error {
-	return p.perform(events.ProjectStartStart, events.ProjectStartDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(wrappers, events.ServiceStartStart, events.ServiceStart, func(service Service) error {
-			return service.Start()
+// StartContext starts the specified services (like docker start).
+func (p *Project) StartContext(ctx context.Context, services ...string) error {
+	return p.perform(ctx, events.ProjectStartStart, events.ProjectStartDone, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, wrappers, events.ServiceStartStart, events.ServiceStart, func(service Service) error {
+			return service.StartContext(ctx)
 		})
 	}), nil)
 }
 
-// Run executes a one off command (like `docker run image command`).
+// Run executes a one off command (like `docker run image command`) with the
+// default options.
 func (p *Project) Run(ctx context.Context, serviceName string, commandParts []string) (int, error) {
+	return p.RunWithOptions(ctx, serviceName, commandParts, options.Run{})
+}
+
+// RunWithOptions executes a one off command with the specified options. Unless
+// NoDeps is set, the transitive dependencies of the service (links,
+// depends_on) are brought up first, and only them. Their names are logged and
+// sent, comma separated, as the dependencies data of the ServiceRunStart
+// event.
+func (p *Project) RunWithOptions(ctx context.Context, serviceName string, commandParts []string, runOptions options.Run) (int, error) {
 	if !p.ServiceConfigs.Has(serviceName) {
 		return 1, fmt.Errorf("%s is not defined in the template", serviceName)
 	}
 
-	var exitCode int
-	err := p.forEach([]string{}, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(wrappers, events.ServiceRunStart, events.ServiceRun, func(service Service) error {
-			if service.Name() == serviceName {
-				code, err := service.Run(ctx, commandParts)
-				exitCode = code
+	service, err := p.CreateService(serviceName)
+	if err != nil {
+		return 1, err
+	}
+
+	dependencies := []string{}
+	if !runOptions.NoDeps {
+		if dependencies, err = p.dependencies(serviceName); err != nil {
+			return 1, err
+		}
+	}
+	if len(dependencies) != 0 {
+		log.Infof("Starting %s for %s", strings.Join(dependencies, ", "), serviceName)
+		if err := p.up(ctx, options.Up{}, dependencies...); err != nil {
+			return 1, err
+		}
+		if err := p.waitForConditions(ctx, service); err != nil {
+			return 1, err
+		}
+	}
+
+	p.Notify(events.ServiceRunStart, serviceName, map[string]string{
+		"dependencies": strings.Join(dependencies, ","),
+	})
+	exitCode, err := service.RunWithOptions(ctx, commandParts, runOptions)
+	if err != nil {
+		return exitCode, err
+	}
+	p.Notify(events.ServiceRun, serviceName, nil)
+	return exitCode, nil
+}
+
+// waitForConditions waits for the depends_on conditions of the specified
+// service to be met.
+func (p *Project) waitForConditions(ctx context.Context, service Service) error {
+	for _, dep := range service.DependentServices() {
+		if !p.ServiceConfigs.Has(dep.Target) {
+			continue
+		}
+		target, err := p.CreateService(dep.Target)
+		if err != nil {
+			return err
+		}
+		if err := waitForCondition(ctx, target, dep.Condition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependencies returns the transitive dependencies of the specified service
+// defined in the project, each one after its own dependencies.
+func (p *Project) dependencies(serviceName string) ([]string, error) {
+	dependencies := []string{}
+	visited := map[string]bool{serviceName: true}
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		service, err := p.CreateService(name)
+		if err != nil {
+			return err
+		}
+		for _, dep := range service.DependentServices() {
+			if visited[dep.Target] || !p.ServiceConfigs.Has(dep.Target) {
+				continue
+			}
+			visited[dep.Target] = true
+			if err := visit(dep.Target); err != nil {
 				return err
 			}
-			return nil
-		})
-	}), func(service Service) error {
-		return service.Create(options.Create{})
-	})
-	return exitCode, err
+			dependencies = append(dependencies, dep.Target)
+		}
+		return nil
+	}
+
+	return dependencies, visit(serviceName)
 }
 
-// Up creates and starts the specified services (kinda like docker run).
-func (p *Project) Up(options options.Up, services ...string) error {
-	return p.perform(events.ProjectUpStart, events.ProjectUpDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(wrappers, events.ServiceUpStart, events.ServiceUp, func(service Service) error {
-			return service.Up(options)
+// Exec executes a command in a running container of the specified service
+// (like `docker exec`), the one with the specified number, and returns the
+// exit code of the command.
+func (p *Project) Exec(ctx context.Context, serviceName string, number int, commandParts []string, options options.Exec) (int, error) {
+	if !p.ServiceConfigs.Has(serviceName) {
+		return 1, fmt.Errorf("%s is not defined in the template", serviceName)
+	}
+
+	service, err := p.CreateService(serviceName)
+	if err != nil {
+		return 1, err
+	}
+
+	return service.Exec(ctx, number, commandParts, options)
+}
+
+// UpContext creates and starts the specified services (kinda like docker run).
+// If options.Transactional is set, the changes are rolled back if any of the
+// services fails. If options.AbortOnContainerExit or options.ExitCodeFrom is
+// set, it then blocks until a container exits and stops the services. It
+// returns an *ExitError if the container exited with a non-zero code.
+// If options.Attached is set, the logs of the containers are followed as soon
+// as they start, and it blocks until they all stop, or stops the services
+// when the context is done.
+func (p *Project) UpContext(ctx context.Context, options options.Up, services ...string) error {
+	if options.ExitCodeFrom != "" && !p.ServiceConfigs.Has(options.ExitCodeFrom) {
+		return fmt.Errorf("No such service: %s", options.ExitCodeFrom)
+	}
+
+	var follower *logFollower
+	if options.Attached {
+		follower = p.attach()
+		defer p.detach(follower)
+	}
+
+	// The containers which exit before the wait begins have to be waited for
+	// too.
+	var starts *startRecord
+	if options.AbortOnContainerExit || options.ExitCodeFrom != "" {
+		starts = p.recordStarts()
+		defer p.stopRecordingStarts(starts)
+	}
+
+	var err error
+	if options.Transactional {
+		err = p.upInTransaction(ctx, options, services...)
+	} else {
+		err = p.up(ctx, options, services...)
+	}
+	switch {
+	case err != nil:
+		return err
+	case options.AbortOnContainerExit || options.ExitCodeFrom != "":
+		return p.waitAndStop(ctx, options, starts, services...)
+	case follower != nil:
+		return p.waitAttached(ctx, follower, services...)
+	default:
+		return nil
+	}
+}
+
+func (p *Project) upInTransaction(ctx context.Context, options options.Up, services ...string) error {
+	tx := &Transaction{}
+	if err := p.up(WithTransaction(ctx, tx), options, services...); err != nil {
+		log.Errorf("Failed to bring the project up, rolling back: %v", err)
+		// The rollback has to run even if the operation was cancelled.
+		if rollbackErr := tx.Rollback(context.Background()); rollbackErr != nil {
+			log.Errorf("Failed to roll back: %v", rollbackErr)
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (p *Project) up(ctx context.Context, options options.Up, services ...string) error {
+	return p.perform(ctx, events.ProjectUpStart, events.ProjectUpDone, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, wrappers, events.ServiceUpStart, events.ServiceUp, func(service Service) error {
+			return service.UpContext(ctx, options)
 		})
 	}), func(service Service) error {
-		return service.Create(options.Create)
+		return service.CreateContext(ctx, options.Create)
 	})
 }
 
-// Log aggregates and prints out the logs for the specified services.
-func (p *Project) Log(follow bool, services ...string) error {
-	return p.forEach(services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(nil, events.NoEvent, events.NoEvent, func(service Service) error {
-			return service.Log(follow)
+// LogContext aggregates and prints out the logs for the specified services.
+func (p *Project) LogContext(ctx context.Context, options options.Log, services ...string) error {
+	return p.forEach(ctx, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, nil, events.NoEvent, events.NoEvent, func(service Service) error {
+			return service.LogContext(ctx, options)
 		})
 	}), nil)
 }
 
-// Scale scales the specified services.
-func (p *Project) Scale(timeout int, servicesScale map[string]int) error {
+// ScaleContext scales the specified services.
+func (p *Project) ScaleContext(ctx context.Context, timeout int, servicesScale map[string]int) error {
 	// This code is a bit verbose but I wanted to parse everything up front
 	order := make([]string, 0, 0)
 	services := make(map[string]Service)
@@ -411,40 +641,42 @@ func (p *Project) Scale(timeout int, servicesScale map[string]int) error {
 	for _, name := range order {
 		scale := servicesScale[name]
 		log.Infof("Setting scale %s=%d...", name, scale)
-		err := services[name].Scale(scale, timeout)
+		err := services[name].ScaleContext(ctx, scale, timeout)
 		if err != nil {
-			return fmt.Errorf("Failed to set the scale %s=%d: %v", name, scale, err)
+			errs := &MultiError{}
+			errs.add(err, name, fmt.Sprintf("set the scale %d of", scale))
+			return errs
 		}
 	}
 	return nil
 }
 
-// Pull pulls the specified services (like docker pull).
-func (p *Project) Pull(services ...string) error {
-	return p.forEach(services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(nil, events.ServicePullStart, events.ServicePull, func(service Service) error {
-			return service.Pull()
+// PullContext pulls the specified services (like docker pull).
+func (p *Project) PullContext(ctx context.Context, services ...string) error {
+	return p.forEach(ctx, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, nil, events.ServicePullStart, events.ServicePull, func(service Service) error {
+			return service.PullContext(ctx)
 		})
 	}), nil)
 }
 
 // listStoppedContainers lists the stopped containers for the specified services.
-func (p *Project) listStoppedContainers(services ...string) ([]string, error) {
+func (p *Project) listStoppedContainers(ctx context.Context, services ...string) ([]string, error) {
 	stoppedContainers := []string{}
-	err := p.forEach(services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(nil, events.NoEvent, events.NoEvent, func(service Service) error {
-			containers, innerErr := service.Containers()
+	err := p.forEach(ctx, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, nil, events.NoEvent, events.NoEvent, func(service Service) error {
+			containers, innerErr := service.ContainersContext(ctx)
 			if innerErr != nil {
 				return innerErr
 			}
 
 			for _, container := range containers {
-				running, innerErr := container.IsRunning()
+				running, innerErr := container.IsRunningContext(ctx)
 				if innerErr != nil {
 					log.Error(innerErr)
 				}
 				if !running {
-					containerID, innerErr := container.ID()
+					containerID, innerErr := container.IDContext(ctx)
 					if innerErr != nil {
 						log.Error(innerErr)
 					}
@@ -461,9 +693,9 @@ func (p *Project) listStoppedContainers(services ...string) ([]string, error) {
 	return stoppedContainers, nil
 }
 
-// Delete removes the specified services (like docker rm).
-func (p *Project) Delete(options options.Delete, services ...string) error {
-	stoppedContainers, err := p.listStoppedContainers(services...)
+// DeleteContext removes the specified services (like docker rm).
+func (p *Project) DeleteContext(ctx context.Context, options options.Delete, services ...string) error {
+	stoppedContainers, err := p.listStoppedContainers(ctx, services...)
 	if err != nil {
 		return err
 	}
@@ -475,44 +707,44 @@ func (p *Project) Delete(options options.Delete, services ...string) error {
 	if options.BeforeDeleteCallback != nil && !options.BeforeDeleteCallback(stoppedContainers) {
 		return nil
 	}
-	return p.perform(events.ProjectDeleteStart, events.ProjectDeleteDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(nil, events.ServiceDeleteStart, events.ServiceDelete, func(service Service) error {
-			return service.Delete(options)
+	return p.perform(ctx, events.ProjectDeleteStart, events.ProjectDeleteDone, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, nil, events.ServiceDeleteStart, events.ServiceDelete, func(service Service) error {
+			return service.DeleteContext(ctx, options)
 		})
 	}), nil)
 }
 
-// Kill kills the specified services (like docker kill).
-func (p *Project) Kill(signal string, services ...string) error {
-	return p.perform(events.ProjectKillStart, events.ProjectKillDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(nil, events.ServiceKillStart, events.ServiceKill, func(service Service) error {
-			return service.Kill(signal)
+// KillContext kills the specified services (like docker kill).
+func (p *Project) KillContext(ctx context.Context, signal string, services ...string) error {
+	return p.perform(ctx, events.ProjectKillStart, events.ProjectKillDone, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, nil, events.ServiceKillStart, events.ServiceKill, func(service Service) error {
+			return service.KillContext(ctx, signal)
 		})
 	}), nil)
 }
 
-// Pause pauses the specified services containers (like docker pause).
-func (p *Project) Pause(services ...string) error {
-	return p.perform(events.ProjectPauseStart, events.ProjectPauseDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(nil, events.ServicePauseStart, events.ServicePause, func(service Service) error {
-			return service.Pause()
+// PauseContext pauses the specified services containers (like docker pause).
+func (p *Project) PauseContext(ctx context.Context, services ...string) error {
+	return p.perform(ctx, events.ProjectPauseStart, events.ProjectPauseDone, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, nil, events.ServicePauseStart, events.ServicePause, func(service Service) error {
+			return service.PauseContext(ctx)
 		})
 	}), nil)
 }
 
-// Unpause pauses the specified services containers (like docker pause).
-func (p *Project) Unpause(services ...string) error {
-	return p.perform(events.ProjectUnpauseStart, events.ProjectUnpauseDone, services, wrapperAction(func(wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
-		wrapper.Do(nil, events.ServiceUnpauseStart, events.ServiceUnpause, func(service Service) error {
-			return service.Unpause()
+// UnpauseContext pauses the specified services containers (like docker pause).
+func (p *Project) UnpauseContext(ctx context.Context, services ...string) error {
+	return p.perform(ctx, events.ProjectUnpauseStart, events.ProjectUnpauseDone, services, wrapperAction(func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.Do(ctx, nil, events.ServiceUnpauseStart, events.ServiceUnpause, func(service Service) error {
+			return service.UnpauseContext(ctx)
 		})
 	}), nil)
 }
 
-func (p *Project) perform(start, done events.EventType, services []string, action wrapperAction, cycleAction serviceAction) error {
+func (p *Project) perform(ctx context.Context, start, done events.EventType, services []string, action wrapperAction, cycleAction serviceAction) error {
 	p.Notify(start, "", nil)
 
-	err := p.forEach(services, action, cycleAction)
+	err := p.forEach(ctx, services, action, cycleAction)
 
 	p.Notify(done, "", nil)
 	return err
@@ -522,7 +754,7 @@ func isSelected(wrapper *serviceWrapper, selected map[string]bool) bool {
 	return len(selected) == 0 || selected[wrapper.name]
 }
 
-func (p *Project) forEach(services []string, action wrapperAction, cycleAction serviceAction) error {
+func (p *Project) forEach(ctx context.Context, services []string, action wrapperAction, cycleAction serviceAction) error {
 	selected := make(map[string]bool)
 	wrappers := make(map[string]*serviceWrapper)
 
@@ -530,10 +762,28 @@ func (p *Project) forEach(services []string, action wrapperAction, cycleAction s
 		selected[s] = true
 	}
 
-	return p.traverse(true, selected, wrappers, action, cycleAction)
+	if p.context.FailFast {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		action = failFast(action, cancel)
+	}
+
+	return p.traverse(ctx, true, selected, wrappers, action, cycleAction)
 }
 
-func (p *Project) startService(wrappers map[string]*serviceWrapper, history []string, selected, launched map[string]bool, wrapper *serviceWrapper, action wrapperAction, cycleAction serviceAction) error {
+// failFast wraps the specified action so that the first service failing
+// cancels the operation: the services that are still pending are not started
+// and the in-flight calls are cancelled. The operation is cancelled before the
+// dependents of the failed service are woken up, so none of them starts.
+func failFast(action wrapperAction, cancel context.CancelFunc) wrapperAction {
+	return func(ctx context.Context, wrapper *serviceWrapper, wrappers map[string]*serviceWrapper) {
+		wrapper.failed = cancel
+		action(ctx, wrapper, wrappers)
+	}
+}
+
+func (p *Project) startService(ctx context.Context, wrappers map[string]*serviceWrapper, history []string, selected, launched map[string]bool, wrapper *serviceWrapper, action wrapperAction, cycleAction serviceAction) error {
 	if launched[wrapper.name] {
 		return nil
 	}
@@ -568,7 +818,7 @@ func (p *Project) startService(wrappers map[string]*serviceWrapper, history []st
 			continue
 		}
 
-		err := p.startService(wrappers, history, selected, launched, target, action, cycleAction)
+		err := p.startService(ctx, wrappers, history, selected, launched, target, action, cycleAction)
 		if err != nil {
 			return err
 		}
@@ -576,7 +826,7 @@ func (p *Project) startService(wrappers map[string]*serviceWrapper, history []st
 
 	if isSelected(wrapper, selected) {
 		log.Debugf("Launching action for %s", wrapper.name)
-		go action(wrapper, wrappers)
+		go action(ctx, wrapper, wrappers)
 	} else {
 		wrapper.Ignore()
 	}
@@ -584,7 +834,7 @@ func (p *Project) startService(wrappers map[string]*serviceWrapper, history []st
 	return nil
 }
 
-func (p *Project) traverse(start bool, selected map[string]bool, wrappers map[string]*serviceWrapper, action wrapperAction, cycleAction serviceAction) error {
+func (p *Project) traverse(ctx context.Context, start bool, selected map[string]bool, wrappers map[string]*serviceWrapper, action wrapperAction, cycleAction serviceAction) error {
 	restart := false
 	wrapperList := []string{}
 
@@ -614,12 +864,12 @@ func (p *Project) traverse(start bool, selected map[string]bool, wrappers map[st
 	launched := map[string]bool{}
 
 	for _, wrapper := range wrappers {
-		if err := p.startService(wrappers, []string{}, selected, launched, wrapper, action, cycleAction); err != nil {
+		if err := p.startService(ctx, wrappers, []string{}, selected, launched, wrapper, action, cycleAction); err != nil {
 			return err
 		}
 	}
 
-	var firstError error
+	errs := &MultiError{}
 
 	for _, wrapper := range wrappers {
 		if !isSelected(wrapper, selected) {
@@ -629,21 +879,50 @@ func (p *Project) traverse(start bool, selected map[string]bool, wrappers map[st
 			restart = true
 		} else if err != nil {
 			log.Errorf("Failed to start: %s : %v", wrapper.name, err)
-			if firstError == nil {
-				firstError = err
-			}
+			errs.add(err, wrapper.name, wrapper.operation)
 		}
 	}
 
+	// With fail fast, the failure cancelled the other services, only keep it.
+	if p.context.FailFast {
+		errs.dropCanceled()
+	}
+
 	if restart {
 		if p.ReloadCallback != nil {
 			if err := p.ReloadCallback(); err != nil {
 				log.Errorf("Failed calling callback: %v", err)
 			}
 		}
-		return p.traverse(false, selected, wrappers, action, cycleAction)
+		return p.traverse(ctx, false, selected, wrappers, action, cycleAction)
+	}
+	return errs.errorOrNil()
+}
+
+// acquireSlot blocks until the service can run its action without exceeding
+// the parallelism of the project, or until the context is done.
+func (p *Project) acquireSlot(ctx context.Context) error {
+	p.slotsOnce.Do(func() {
+		if p.context.Parallelism > 0 {
+			p.slots = make(chan struct{}, p.context.Parallelism)
+		}
+	})
+	if p.slots == nil {
+		return nil
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releaseSlot releases a slot acquired with acquireSlot.
+func (p *Project) releaseSlot() {
+	if p.slots != nil {
+		<-p.slots
 	}
-	return firstError
 }
 
 // AddListener adds the specified listener to the project.
@@ -667,6 +946,10 @@ func (p *Project) Notify(eventType events.EventType, serviceName string, data ma
 		return
 	}
 
+	if eventType == events.ContainerStarted {
+		p.containerStarted(serviceName, data["name"])
+	}
+
 	event := events.Event{
 		EventType:   eventType,
 		ServiceName: serviceName,
@@ -1,12 +1,15 @@
 package project
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project/events"
 	"github.com/hyperhq/libcompose/project/options"
 	"github.com/hyperhq/libcompose/yaml"
 	"github.com/stretchr/testify/assert"
@@ -33,11 +36,11 @@ func (t *TestService) Name() string {
 	return t.name
 }
 
-func (t *TestService) Run(ctx context.Context, commandParts []string) (int, error) {
+func (t *TestService) RunWithOptions(ctx context.Context, commandParts []string, options options.Run) (int, error) {
 	return 0, nil
 }
 
-func (t *TestService) Create(options options.Create) error {
+func (t *TestService) CreateContext(ctx context.Context, options options.Create) error {
 	key := t.name + ".create"
 	t.factory.Counts[key] = t.factory.Counts[key] + 1
 	return nil
@@ -66,11 +69,11 @@ func TestTwoCall(t *testing.T) {
 	p.ServiceConfigs = config.NewServiceConfigs()
 	p.ServiceConfigs.Add("foo", &config.ServiceConfig{})
 
-	if err := p.Create(options.Create{}, "foo"); err != nil {
+	if err := p.CreateContext(context.Background(), options.Create{}, "foo"); err != nil {
 		t.Fatal(err)
 	}
 
-	if err := p.Create(options.Create{}, "foo"); err != nil {
+	if err := p.CreateContext(context.Background(), options.Create{}, "foo"); err != nil {
 		t.Fatal(err)
 	}
 
@@ -79,6 +82,183 @@ func TestTwoCall(t *testing.T) {
 	}
 }
 
+func TestCancelledContext(t *testing.T) {
+	factory := &TestServiceFactory{
+		Counts: map[string]int{},
+	}
+
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("foo", &config.ServiceConfig{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := p.CreateContext(ctx, options.Create{}, "foo")
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) || multiErr.Errors[0].Err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+
+	if factory.Counts["foo.create"] != 0 {
+		t.Fatal("Should not have created a cancelled service")
+	}
+}
+
+func TestDependsOnOrder(t *testing.T) {
+	factory := &TestHookServiceFactory{}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "app"}}})
+	p.ServiceConfigs.Add("app", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "db"}}})
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+
+	if err := p.CreateContext(context.Background(), options.Create{}); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"db", "app", "web"}, factory.called("create"))
+}
+
+func TestDependsOnCycle(t *testing.T) {
+	factory := &TestHookServiceFactory{}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("foo", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "bar"}}})
+	p.ServiceConfigs.Add("bar", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "foo"}}})
+
+	err := p.CreateContext(context.Background(), options.Create{})
+	if err == nil || !strings.HasPrefix(err.Error(), "Cycle detected in path") {
+		t.Fatalf("expected a cycle to be detected, got %v", err)
+	}
+}
+
+func TestRunDependencies(t *testing.T) {
+	factory := &TestHookServiceFactory{}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{Links: yaml.MaporColonSlice{"app"}})
+	p.ServiceConfigs.Add("app", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "db"}}})
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+	p.ServiceConfigs.Add("cache", &config.ServiceConfig{})
+
+	listener := make(chan events.Event, 100)
+	p.AddListener(listener)
+
+	if _, err := p.Run(context.Background(), "web", []string{"ls"}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"db", "app"}, factory.called("up"))
+
+	var dependencies string
+	for len(listener) != 0 {
+		if event := <-listener; event.EventType == events.ServiceRunStart {
+			dependencies = event.Data["dependencies"]
+		}
+	}
+	assert.Equal(t, "db,app", dependencies)
+
+	factory.calls = nil
+	if _, err := p.RunWithOptions(context.Background(), "web", []string{"ls"}, options.Run{NoDeps: true}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, factory.called("up"))
+}
+
+func TestParallelism(t *testing.T) {
+	var running, maxRunning int
+	factory := &TestHookServiceFactory{hooks: map[string]func(ctx context.Context, operation string) error{}}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+		Parallelism:    2,
+	})
+	p.ServiceConfigs = config.NewServiceConfigs()
+	for i := 0; i < 8; i++ {
+		name := fmt.Sprintf("service%d", i)
+		p.ServiceConfigs.Add(name, &config.ServiceConfig{})
+		factory.hooks[name] = func(ctx context.Context, operation string) error {
+			factory.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			factory.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			factory.Lock()
+			running--
+			factory.Unlock()
+			return nil
+		}
+	}
+
+	if err := p.CreateContext(context.Background(), options.Create{}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, factory.called("create"), 8)
+	if maxRunning > 2 {
+		t.Fatalf("expected at most 2 services at the same time, got %d", maxRunning)
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	factory := &TestHookServiceFactory{hooks: failing("db")}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+		FailFast:       true,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+	p.ServiceConfigs.Add("app", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "db"}}})
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "app"}}})
+
+	err := p.CreateContext(context.Background(), options.Create{})
+	var multiErr *MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("expected a MultiError, got %v", err)
+	}
+	// The services cancelled by the failure of db are not reported.
+	assert.Equal(t, []string{"db"}, multiErr.Services())
+	assert.Equal(t, "db failed", multiErr.Errors[0].Err.Error())
+	assert.Equal(t, []string{"db"}, factory.called("create"))
+}
+
+func TestPortByNumber(t *testing.T) {
+	factory := &TestHookServiceFactory{
+		containers: map[string][]Container{
+			"web": {
+				&TestConditionContainer{status: &ContainerStatus{Number: 3}, port: "0.0.0.0:32770"},
+				&TestConditionContainer{status: &ContainerStatus{Number: 1}, port: "0.0.0.0:32768"},
+			},
+		},
+	}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{})
+
+	port, err := p.PortContext(context.Background(), 3, "tcp", "web", "80")
+	assert.Nil(t, err)
+	assert.Equal(t, "0.0.0.0:32770", port)
+
+	_, err = p.PortContext(context.Background(), 2, "tcp", "web", "80")
+	assert.NotNil(t, err)
+}
+
 func TestParseWithBadContent(t *testing.T) {
 	p := NewProject(nil, &Context{
 		ComposeBytes: [][]byte{
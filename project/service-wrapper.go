@@ -1,21 +1,58 @@
 package project
 
 import (
+	"fmt"
 	"sync"
+	"time"
+
+	"golang.org/x/net/context"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/hyperhq/libcompose/project/events"
 )
 
+// conditionEvents holds the actions that start containers, and thus need the
+// depends_on conditions of their dependencies to be met.
+var conditionEvents = map[events.EventType]bool{
+	events.ServiceUpStart:      true,
+	events.ServiceStartStart:   true,
+	events.ServiceRestartStart: true,
+}
+
+// operations holds the name of the operation started by each event, used to
+// report failures.
+var operations = map[events.EventType]string{
+	events.ServiceUpStart:      "up",
+	events.ServiceCreateStart:  "create",
+	events.ServiceDeleteStart:  "delete",
+	events.ServiceRestartStart: "restart",
+	events.ServicePullStart:    "pull",
+	events.ServiceKillStart:    "kill",
+	events.ServiceStartStart:   "start",
+	events.ServiceBuildStart:   "build",
+	events.ServicePauseStart:   "pause",
+	events.ServiceUnpauseStart: "unpause",
+	events.ServiceStopStart:    "stop",
+	events.ServiceRunStart:     "run",
+}
+
+// healthPollInterval is the interval at which the health of the containers of
+// a dependency is checked.
+var healthPollInterval = time.Second
+
 type serviceWrapper struct {
-	name    string
-	service Service
-	done    sync.WaitGroup
-	state   ServiceState
-	err     error
-	project *Project
-	noWait  bool
-	ignored map[string]bool
+	name      string
+	service   Service
+	done      sync.WaitGroup
+	state     ServiceState
+	err       error
+	operation string
+	project   *Project
+	noWait    bool
+	ignored   map[string]bool
+	// failed, if set, is called when the service fails, before its
+	// dependents are woken up.
+	failed func()
 }
 
 func newServiceWrapper(name string, p *Project) (*serviceWrapper, error) {
@@ -59,7 +96,7 @@ func (s *serviceWrapper) Ignore() {
 	s.project.Notify(events.ServiceUpIgnored, s.service.Name(), nil)
 }
 
-func (s *serviceWrapper) waitForDeps(wrappers map[string]*serviceWrapper) bool {
+func (s *serviceWrapper) waitForDeps(ctx context.Context, wrappers map[string]*serviceWrapper, checkConditions bool) bool {
 	if s.noWait {
 		return true
 	}
@@ -70,11 +107,22 @@ func (s *serviceWrapper) waitForDeps(wrappers map[string]*serviceWrapper) bool {
 		}
 
 		if wrapper, ok := wrappers[dep.Target]; ok {
-			if wrapper.Wait() == ErrRestart {
+			err := wrapper.WaitContext(ctx)
+			if err == ErrRestart {
 				s.project.Notify(events.ProjectReload, wrapper.service.Name(), nil)
 				s.err = ErrRestart
 				return false
 			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				s.err = ctxErr
+				return false
+			}
+			if checkConditions {
+				if err := waitForCondition(ctx, wrapper.service, dep.Condition); err != nil {
+					s.err = err
+					return false
+				}
+			}
 		} else {
 			log.Errorf("Failed to find %s", dep.Target)
 		}
@@ -83,16 +131,101 @@ func (s *serviceWrapper) waitForDeps(wrappers map[string]*serviceWrapper) bool {
 	return true
 }
 
-func (s *serviceWrapper) Do(wrappers map[string]*serviceWrapper, start, done events.EventType, action func(service Service) error) {
+func waitForCondition(ctx context.Context, service Service, condition DependencyCondition) error {
+	switch condition {
+	case "", ConditionServiceStarted:
+		return nil
+	case ConditionServiceHealthy:
+		return waitForHealthy(ctx, service)
+	case ConditionServiceCompletedSuccessfully:
+		return waitForCompletion(ctx, service)
+	default:
+		return fmt.Errorf("Unknown depends_on condition %s for service %s", condition, service.Name())
+	}
+}
+
+func waitForHealthy(ctx context.Context, service Service) error {
+	for {
+		containers, err := service.ContainersContext(ctx)
+		if err != nil {
+			return err
+		}
+		if len(containers) == 0 {
+			return fmt.Errorf("Service %s has no container to be healthy", service.Name())
+		}
+		healthy := true
+		for _, container := range containers {
+			health, err := container.Health(ctx)
+			if err != nil {
+				return err
+			}
+			switch health {
+			case "healthy":
+			case "unhealthy":
+				return fmt.Errorf("Container %s of service %s is unhealthy", container.Name(), service.Name())
+			case "":
+				return fmt.Errorf("Container %s of service %s has no healthcheck", container.Name(), service.Name())
+			default:
+				healthy = false
+			}
+		}
+		if healthy {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(healthPollInterval):
+		}
+	}
+}
+
+func waitForCompletion(ctx context.Context, service Service) error {
+	containers, err := service.ContainersContext(ctx)
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		return fmt.Errorf("Service %s has no container to complete", service.Name())
+	}
+	for _, container := range containers {
+		exitCode, err := container.Wait(ctx)
+		if err != nil {
+			return err
+		}
+		if exitCode != 0 {
+			return fmt.Errorf("Container %s of service %s exited with code %d", container.Name(), service.Name(), exitCode)
+		}
+	}
+	return nil
+}
+
+func (s *serviceWrapper) Do(ctx context.Context, wrappers map[string]*serviceWrapper, start, done events.EventType, action func(service Service) error) {
 	defer s.done.Done()
+	defer s.notifyFailure()
 
 	if s.state == StateExecuted {
 		return
 	}
 
-	if wrappers != nil && !s.waitForDeps(wrappers) {
+	s.operation = operations[start]
+
+	if wrappers != nil && !s.waitForDeps(ctx, wrappers, conditionEvents[start]) {
+		return
+	}
+
+	// Do not start anything new once the operation has been cancelled.
+	if err := ctx.Err(); err != nil {
+		s.err = err
+		return
+	}
+
+	if err := s.project.acquireSlot(ctx); err != nil {
+		s.err = err
 		return
 	}
+	defer s.project.releaseSlot()
 
 	s.state = StateExecuted
 
@@ -109,7 +242,32 @@ func (s *serviceWrapper) Do(wrappers map[string]*serviceWrapper, start, done eve
 	}
 }
 
+// notifyFailure calls the failed hook if the service failed, errors caused by
+// a cancellation or a restart aside.
+func (s *serviceWrapper) notifyFailure() {
+	if s.failed != nil && s.err != nil && s.err != ErrRestart && s.err != context.Canceled {
+		s.failed()
+	}
+}
+
 func (s *serviceWrapper) Wait() error {
 	s.done.Wait()
 	return s.err
 }
+
+// WaitContext is like Wait but returns the error of the context as soon as it
+// is done, without waiting for the service.
+func (s *serviceWrapper) WaitContext(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.done.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return s.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
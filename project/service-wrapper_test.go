@@ -0,0 +1,104 @@
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project/options"
+	"github.com/hyperhq/libcompose/yaml"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestCancelledDependencyWait(t *testing.T) {
+	p := NewProject(nil, &Context{
+		ServiceFactory: &TestHookServiceFactory{},
+	})
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "db"}}})
+
+	wrappers := map[string]*serviceWrapper{}
+	if err := p.loadWrappers(wrappers, []string{"db", "web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// db never completes, the wait of web must still end with the context.
+	ctx, cancel := context.WithCancel(context.Background())
+	waited := make(chan bool)
+	go func() {
+		waited <- wrappers["web"].waitForDeps(ctx, wrappers, false)
+	}()
+	cancel()
+
+	select {
+	case ok := <-waited:
+		assert.False(t, ok)
+		assert.Equal(t, context.Canceled, wrappers["web"].err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("The wait for the dependencies was not cancelled")
+	}
+}
+
+func newConditionProject(condition string, db Container) (*Project, *TestHookServiceFactory) {
+	factory := &TestHookServiceFactory{
+		containers: map[string][]Container{"db": {db}},
+	}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "db", Condition: condition}}})
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+	return p, factory
+}
+
+func TestDependsOnConditionHealthy(t *testing.T) {
+	defer func(interval time.Duration) { healthPollInterval = interval }(healthPollInterval)
+	healthPollInterval = time.Millisecond
+
+	db := &TestConditionContainer{health: []string{"starting", "starting", "healthy"}}
+	p, factory := newConditionProject("service_healthy", db)
+
+	if err := p.UpContext(context.Background(), options.Up{}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"db", "web"}, factory.called("up"))
+	assert.Equal(t, []string{"healthy"}, db.health)
+}
+
+func TestDependsOnConditionUnhealthy(t *testing.T) {
+	db := &TestConditionContainer{health: []string{"unhealthy"}}
+	p, factory := newConditionProject("service_healthy", db)
+
+	if err := p.UpContext(context.Background(), options.Up{}); err == nil {
+		t.Fatal("expected an error for an unhealthy dependency")
+	}
+	assert.Equal(t, []string{"db"}, factory.called("up"))
+}
+
+func TestDependsOnConditionHealthyWithoutContainer(t *testing.T) {
+	p, factory := newConditionProject("service_healthy", nil)
+	delete(factory.containers, "db")
+
+	if err := p.UpContext(context.Background(), options.Up{}); err == nil {
+		t.Fatal("expected an error for a dependency without container")
+	}
+	assert.Equal(t, []string{"db"}, factory.called("up"))
+}
+
+func TestDependsOnConditionCompletedSuccessfully(t *testing.T) {
+	p, factory := newConditionProject("service_completed_successfully", &TestConditionContainer{})
+	if err := p.UpContext(context.Background(), options.Up{}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"db", "web"}, factory.called("up"))
+
+	p, factory = newConditionProject("service_completed_successfully", &TestConditionContainer{exitCode: 1})
+	if err := p.UpContext(context.Background(), options.Up{}); err == nil {
+		t.Fatal("expected an error for a dependency that exited with a non-zero code")
+	}
+	assert.Equal(t, []string{"db"}, factory.called("up"))
+}
@@ -2,6 +2,7 @@ package project
 
 import (
 	"errors"
+	"io"
 
 	"golang.org/x/net/context"
 
@@ -9,10 +10,42 @@ import (
 	"github.com/hyperhq/libcompose/project/options"
 )
 
-// Service defines what a libcompose service provides.
+// Service defines what a libcompose service provides. The operations taking a
+// context.Context that predate it are suffixed with Context.
 type Service interface {
-	Info(qFlag bool) (InfoSet, error)
+	InfoContext(ctx context.Context, qFlag bool) (InfoSet, error)
 	Name() string
+	BuildContext(ctx context.Context, buildOptions options.Build) error
+	CreateContext(ctx context.Context, options options.Create) error
+	UpContext(ctx context.Context, options options.Up) error
+	StartContext(ctx context.Context) error
+	StopContext(ctx context.Context, timeout int) error
+	DeleteContext(ctx context.Context, options options.Delete) error
+	RestartContext(ctx context.Context, timeout int) error
+	LogContext(ctx context.Context, options options.Log) error
+	PullContext(ctx context.Context) error
+	KillContext(ctx context.Context, signal string) error
+	Config() *config.ServiceConfig
+	DependentServices() []ServiceRelationship
+	ContainersContext(ctx context.Context) ([]Container, error)
+	ScaleContext(ctx context.Context, count int, timeout int) error
+	PauseContext(ctx context.Context) error
+	UnpauseContext(ctx context.Context) error
+	Run(ctx context.Context, commandParts []string) (int, error)
+	RunWithOptions(ctx context.Context, commandParts []string, options options.Run) (int, error)
+	// Exec, CopyTo and CopyFrom operate on the container of the service with
+	// the specified container number.
+	Exec(ctx context.Context, number int, commandParts []string, options options.Exec) (int, error)
+	CopyTo(ctx context.Context, number int, path string, content io.Reader) error
+	CopyFrom(ctx context.Context, number int, path string) (io.ReadCloser, error)
+	PlanUp(ctx context.Context, options options.Up) ([]Action, error)
+	PlanScale(ctx context.Context, count int) ([]Action, error)
+
+	RemoveImageContext(ctx context.Context, imageType options.ImageType) error
+
+	// The operations below predate the context.Context argument, they use
+	// context.Background().
+	Info(qFlag bool) (InfoSet, error)
 	Build(buildOptions options.Build) error
 	Create(options options.Create) error
 	Up(options options.Up) error
@@ -23,14 +56,10 @@ type Service interface {
 	Log(follow bool) error
 	Pull() error
 	Kill(signal string) error
-	Config() *config.ServiceConfig
-	DependentServices() []ServiceRelationship
 	Containers() ([]Container, error)
 	Scale(count int, timeout int) error
 	Pause() error
 	Unpause() error
-	Run(ctx context.Context, commandParts []string) (int, error)
-
 	RemoveImage(imageType options.ImageType) error
 }
 
@@ -70,11 +99,29 @@ const RelTypeIpcNamespace = ServiceRelationshipType("ipc")
 // RelTypeVolumesFrom means the services share some volumes.
 const RelTypeVolumesFrom = ServiceRelationshipType("volumesFrom")
 
+// RelTypeDependsOn means the dependency was explicitly set using 'depends_on'.
+const RelTypeDependsOn = ServiceRelationshipType("dependsOn")
+
+// DependencyCondition defines the state a dependency has to reach before the
+// dependent service is started.
+type DependencyCondition string
+
+// ConditionServiceStarted means the dependency only needs to be started.
+const ConditionServiceStarted = DependencyCondition("service_started")
+
+// ConditionServiceHealthy means the dependency containers need to be healthy.
+const ConditionServiceHealthy = DependencyCondition("service_healthy")
+
+// ConditionServiceCompletedSuccessfully means the dependency containers need
+// to have exited with a zero exit code.
+const ConditionServiceCompletedSuccessfully = DependencyCondition("service_completed_successfully")
+
 // ServiceRelationship holds the relationship information between two services.
 type ServiceRelationship struct {
 	Target, Alias string
 	Type          ServiceRelationshipType
 	Optional      bool
+	Condition     DependencyCondition
 }
 
 // NewServiceRelationship creates a new Relationship based on the specified alias
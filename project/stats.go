@@ -0,0 +1,258 @@
+package project
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// ContainerProcesses holds the processes running in a container, as listed by
+// ps in the container.
+type ContainerProcesses struct {
+	Service   string     `json:"service"`
+	Number    int        `json:"number"`
+	Name      string     `json:"name"`
+	Titles    []string   `json:"titles"`
+	Processes [][]string `json:"processes"`
+}
+
+// ServiceProcesses holds the processes running in the containers of a
+// service, sorted by container number, and their total number.
+type ServiceProcesses struct {
+	Service    string               `json:"service"`
+	Containers []ContainerProcesses `json:"containers"`
+	Total      int                  `json:"total"`
+}
+
+// ResourceUsage holds the resource usage of a container, or a service.
+type ResourceUsage struct {
+	CPUPercent  float64 `json:"cpu_percent"`
+	MemoryUsage uint64  `json:"memory_usage"`
+	MemoryLimit uint64  `json:"memory_limit"`
+	NetworkRx   uint64  `json:"network_rx"`
+	NetworkTx   uint64  `json:"network_tx"`
+	BlockRead   uint64  `json:"block_read"`
+	BlockWrite  uint64  `json:"block_write"`
+}
+
+func (r *ResourceUsage) add(other ResourceUsage) {
+	r.CPUPercent += other.CPUPercent
+	r.MemoryUsage += other.MemoryUsage
+	r.MemoryLimit += other.MemoryLimit
+	r.NetworkRx += other.NetworkRx
+	r.NetworkTx += other.NetworkTx
+	r.BlockRead += other.BlockRead
+	r.BlockWrite += other.BlockWrite
+}
+
+// ContainerStats holds a sample of the resource usage of a container.
+type ContainerStats struct {
+	Service string `json:"service"`
+	Number  int    `json:"number"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	ResourceUsage
+}
+
+// ServiceStats holds the latest resource usage of the containers of a
+// service, sorted by container number, and their total.
+type ServiceStats struct {
+	Service    string           `json:"service"`
+	Containers []ContainerStats `json:"containers"`
+	Total      ResourceUsage    `json:"total"`
+}
+
+// statsInterval is the interval at which Stats sends the resource usage of
+// the project, if any container reported a new sample.
+var statsInterval = time.Second
+
+// Top lists the processes running in the running containers of the
+// specified services, or of every service if none is specified, grouped by
+// service.
+func (p *Project) Top(ctx context.Context, services ...string) ([]ServiceProcesses, error) {
+	names, err := p.selectedServices(services)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	result := []ServiceProcesses{}
+	for _, name := range names {
+		service, err := p.CreateService(name)
+		if err != nil {
+			return nil, err
+		}
+
+		containers, err := service.ContainersContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		serviceProcesses := ServiceProcesses{
+			Service:    name,
+			Containers: []ContainerProcesses{},
+		}
+		for _, container := range containers {
+			processes, err := container.Top(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if processes == nil {
+				continue
+			}
+			serviceProcesses.Containers = append(serviceProcesses.Containers, *processes)
+			serviceProcesses.Total += len(processes.Processes)
+		}
+		sort.Sort(processesByNumber(serviceProcesses.Containers))
+
+		result = append(result, serviceProcesses)
+	}
+
+	return result, nil
+}
+
+// Stats streams the resource usage of the running containers of the specified
+// services, or of every service if none is specified, grouped by service. A
+// new snapshot is sent at most every statsInterval, when containers reported
+// new samples. The channel is closed when the context is done or when every
+// container stopped reporting.
+func (p *Project) Stats(ctx context.Context, services ...string) (<-chan []ServiceStats, error) {
+	names, err := p.selectedServices(services)
+	if err != nil {
+		return nil, err
+	}
+
+	// The streams already opened are closed with this context when a later
+	// container fails to report its usage.
+	ctx, cancel := context.WithCancel(ctx)
+
+	streams := []<-chan ContainerStats{}
+	for _, name := range names {
+		service, err := p.CreateService(name)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		containers, err := service.ContainersContext(ctx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		for _, container := range containers {
+			stream, err := container.Stats(ctx)
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			if stream != nil {
+				streams = append(streams, stream)
+			}
+		}
+	}
+
+	samples := make(chan ContainerStats)
+	var wg sync.WaitGroup
+	for _, stream := range streams {
+		wg.Add(1)
+		go func(stream <-chan ContainerStats) {
+			defer wg.Done()
+			for sample := range stream {
+				select {
+				case samples <- sample:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(stream)
+	}
+	go func() {
+		wg.Wait()
+		close(samples)
+	}()
+
+	out := make(chan []ServiceStats)
+	go func() {
+		defer cancel()
+		defer close(out)
+
+		ticker := time.NewTicker(statsInterval)
+		defer ticker.Stop()
+
+		latest := map[string]ContainerStats{}
+		changed := false
+		send := func() bool {
+			changed = false
+			select {
+			case out <- groupStats(latest):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case sample, ok := <-samples:
+				if !ok {
+					if changed {
+						send()
+					}
+					return
+				}
+				latest[sample.Name] = sample
+				changed = true
+			case <-ticker.C:
+				if changed && !send() {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// groupStats groups the specified samples by service, sorted by service.
+func groupStats(samples map[string]ContainerStats) []ServiceStats {
+	services := map[string]*ServiceStats{}
+	names := []string{}
+	for _, sample := range samples {
+		serviceStats, ok := services[sample.Service]
+		if !ok {
+			serviceStats = &ServiceStats{
+				Service:    sample.Service,
+				Containers: []ContainerStats{},
+			}
+			services[sample.Service] = serviceStats
+			names = append(names, sample.Service)
+		}
+		serviceStats.Containers = append(serviceStats.Containers, sample)
+		serviceStats.Total.add(sample.ResourceUsage)
+	}
+	sort.Strings(names)
+
+	result := make([]ServiceStats, 0, len(names))
+	for _, name := range names {
+		sort.Sort(statsByNumber(services[name].Containers))
+		result = append(result, *services[name])
+	}
+	return result
+}
+
+type processesByNumber []ContainerProcesses
+
+func (c processesByNumber) Len() int           { return len(c) }
+func (c processesByNumber) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c processesByNumber) Less(i, j int) bool { return c[i].Number < c[j].Number }
+
+type statsByNumber []ContainerStats
+
+func (c statsByNumber) Len() int           { return len(c) }
+func (c statsByNumber) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c statsByNumber) Less(i, j int) bool { return c[i].Number < c[j].Number }
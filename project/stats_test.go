@@ -0,0 +1,110 @@
+package project
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestTop(t *testing.T) {
+	factory := &TestHookServiceFactory{
+		containers: map[string][]Container{
+			"db": {
+				&TestConditionContainer{},
+			},
+			"web": {
+				&TestConditionContainer{processes: &ContainerProcesses{Service: "web", Number: 2, Name: "web_2", Processes: [][]string{{"1", "nginx"}}}},
+				&TestConditionContainer{processes: &ContainerProcesses{Service: "web", Number: 1, Name: "web_1", Processes: [][]string{{"1", "nginx"}, {"7", "nginx"}}}},
+			},
+		},
+	}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{})
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+
+	top, err := p.Top(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, top, 2)
+	assert.Equal(t, "db", top[0].Service)
+	assert.Empty(t, top[0].Containers)
+	assert.Equal(t, "web", top[1].Service)
+	assert.Equal(t, 3, top[1].Total)
+	assert.Equal(t, "web_1", top[1].Containers[0].Name)
+	assert.Equal(t, "web_2", top[1].Containers[1].Name)
+
+	if _, err := p.Top(context.Background(), "cache"); err == nil {
+		t.Fatal("expected an error for an undefined service")
+	}
+}
+
+func TestStats(t *testing.T) {
+	factory := &TestHookServiceFactory{
+		containers: map[string][]Container{
+			"db": {
+				&TestConditionContainer{stats: []ContainerStats{
+					{Service: "db", Number: 1, Name: "db_1", ResourceUsage: ResourceUsage{CPUPercent: 5, MemoryUsage: 100}},
+				}},
+			},
+			"web": {
+				&TestConditionContainer{stats: []ContainerStats{
+					{Service: "web", Number: 2, Name: "web_2", ResourceUsage: ResourceUsage{CPUPercent: 1, MemoryUsage: 10}},
+					{Service: "web", Number: 2, Name: "web_2", ResourceUsage: ResourceUsage{CPUPercent: 2, MemoryUsage: 20, NetworkRx: 3}},
+				}},
+				&TestConditionContainer{stats: []ContainerStats{
+					{Service: "web", Number: 1, Name: "web_1", ResourceUsage: ResourceUsage{CPUPercent: 3, MemoryUsage: 30, NetworkRx: 4}},
+				}},
+				&TestConditionContainer{},
+			},
+		},
+	}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{})
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+
+	stream, err := p.Stats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last []ServiceStats
+	for snapshot := range stream {
+		last = snapshot
+	}
+
+	assert.Len(t, last, 2)
+	assert.Equal(t, "db", last[0].Service)
+	assert.Equal(t, ResourceUsage{CPUPercent: 5, MemoryUsage: 100}, last[0].Total)
+	assert.Equal(t, "web", last[1].Service)
+	assert.Equal(t, ResourceUsage{CPUPercent: 5, MemoryUsage: 50, NetworkRx: 7}, last[1].Total)
+	assert.Equal(t, "web_1", last[1].Containers[0].Name)
+	assert.Equal(t, "web_2", last[1].Containers[1].Name)
+}
+
+func TestStatsClosesOpenedStreamsOnError(t *testing.T) {
+	opened := &TestConditionContainer{stats: []ContainerStats{{Service: "web", Number: 1, Name: "web_1"}}}
+	factory := &TestHookServiceFactory{
+		containers: map[string][]Container{
+			"web": {opened, &TestConditionContainer{statsErr: errors.New("no stats")}},
+		},
+	}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{})
+
+	if _, err := p.Stats(context.Background()); err == nil {
+		t.Fatal("expected an error when a container fails to report its usage")
+	}
+	assert.Equal(t, context.Canceled, opened.statsCtx.Err())
+}
@@ -0,0 +1,134 @@
+package project
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/hyperhq/libcompose/project/options"
+)
+
+// ContainerStatus holds the status of a container of a service.
+type ContainerStatus struct {
+	Service  string    `json:"service"`
+	Number   int       `json:"number"`
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Image    string    `json:"image"`
+	State    string    `json:"state"`
+	Health   string    `json:"health,omitempty"`
+	ExitCode int       `json:"exit_code"`
+	Ports    []string  `json:"ports"`
+	Created  time.Time `json:"created"`
+	Started  time.Time `json:"started"`
+}
+
+// ContainerStatuses holds a list of ContainerStatus.
+type ContainerStatuses []ContainerStatus
+
+// InfoSet returns the statuses as an InfoSet, one Info per container.
+func (statuses ContainerStatuses) InfoSet() InfoSet {
+	infos := InfoSet{}
+	for _, status := range statuses {
+		infos = append(infos, Info{
+			{Key: "Name", Value: status.Name},
+			{Key: "Service", Value: status.Service},
+			{Key: "Number", Value: strconv.Itoa(status.Number)},
+			{Key: "Image", Value: status.Image},
+			{Key: "State", Value: status.State},
+			{Key: "Health", Value: status.Health},
+			{Key: "ExitCode", Value: strconv.Itoa(status.ExitCode)},
+			{Key: "Ports", Value: strings.Join(status.Ports, ", ")},
+		})
+	}
+	return infos
+}
+
+// Format renders the statuses as a table (format "" or "table"), as JSON
+// (format "json") or with the specified Go template, executed for each
+// container.
+func (statuses ContainerStatuses) Format(format string) (string, error) {
+	switch format {
+	case "", "table":
+		return statuses.InfoSet().String(true), nil
+	case "json":
+		return formatJSON(statuses)
+	default:
+		items := make([]interface{}, 0, len(statuses))
+		for _, status := range statuses {
+			items = append(items, status)
+		}
+		return formatTemplate(format, items)
+	}
+}
+
+// Status returns the status of the containers of the specified services, or
+// of every service if none is specified, sorted by service and number. Only
+// the containers in one of options.States are returned, if any is set.
+func (p *Project) Status(ctx context.Context, options options.Ps, services ...string) (ContainerStatuses, error) {
+	names, err := p.selectedServices(services)
+	if err != nil {
+		return nil, err
+	}
+
+	states := map[string]bool{}
+	for _, state := range options.States {
+		states[state] = true
+	}
+
+	statuses := ContainerStatuses{}
+	for _, name := range names {
+		service, err := p.CreateService(name)
+		if err != nil {
+			return nil, err
+		}
+
+		containers, err := service.ContainersContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, container := range containers {
+			status, err := container.Status(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if status == nil || (len(states) != 0 && !states[status.State]) {
+				continue
+			}
+			statuses = append(statuses, *status)
+		}
+	}
+
+	sort.Stable(byServiceAndNumber(statuses))
+	return statuses, nil
+}
+
+// selectedServices returns the specified services, or every service of the
+// project if none is specified. It fails if a service is not defined.
+func (p *Project) selectedServices(services []string) ([]string, error) {
+	if len(services) == 0 {
+		return p.ServiceConfigs.Keys(), nil
+	}
+	for _, name := range services {
+		if !p.ServiceConfigs.Has(name) {
+			return nil, fmt.Errorf("No such service: %s", name)
+		}
+	}
+	return services, nil
+}
+
+type byServiceAndNumber ContainerStatuses
+
+func (s byServiceAndNumber) Len() int      { return len(s) }
+func (s byServiceAndNumber) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byServiceAndNumber) Less(i, j int) bool {
+	if s[i].Service != s[j].Service {
+		return s[i].Service < s[j].Service
+	}
+	return s[i].Number < s[j].Number
+}
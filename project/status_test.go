@@ -0,0 +1,57 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project/options"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestStatus(t *testing.T) {
+	factory := &TestHookServiceFactory{
+		containers: map[string][]Container{
+			"db": {
+				&TestConditionContainer{status: &ContainerStatus{Service: "db", Number: 2, Name: "db_2", State: "exited", Ports: []string{}}},
+				&TestConditionContainer{status: &ContainerStatus{Service: "db", Number: 1, Name: "db_1", State: "running", Ports: []string{"0.0.0.0:5432->5432/tcp"}}},
+			},
+			"web": {
+				&TestConditionContainer{status: &ContainerStatus{Service: "web", Number: 1, Name: "web_1", State: "running", Ports: []string{}}},
+			},
+		},
+	}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("web", &config.ServiceConfig{})
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+
+	statuses, err := p.Status(context.Background(), options.Ps{}, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"db_1", "db_2"}, []string{statuses[0].Name, statuses[1].Name})
+
+	statuses, err = p.Status(context.Background(), options.Ps{States: []string{"running"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, statuses, 2)
+
+	output, err := statuses.Format("{{.Name}} {{.State}}")
+	assert.Nil(t, err)
+	assert.Equal(t, "db_1 running\nweb_1 running\n", output)
+
+	output, err = statuses[:1].Format("json")
+	assert.Nil(t, err)
+	assert.Contains(t, output, `"ports": [
+      "0.0.0.0:5432->5432/tcp"
+    ]`)
+
+	if _, err := p.Status(context.Background(), options.Ps{}, "unknown"); err == nil {
+		t.Fatal("expected an error for an unknown service")
+	}
+}
@@ -0,0 +1,97 @@
+package project
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// TransactionStep is a change made during a transactional operation.
+type TransactionStep interface {
+	// Commit makes the change permanent, e.g. removes a replaced container.
+	Commit(ctx context.Context) error
+	// Rollback reverts the change.
+	Rollback(ctx context.Context) error
+}
+
+type transactionStep struct {
+	commit, rollback func(ctx context.Context) error
+}
+
+// NewTransactionStep returns a TransactionStep calling the specified functions.
+// Any of them can be nil.
+func NewTransactionStep(commit, rollback func(ctx context.Context) error) TransactionStep {
+	return &transactionStep{commit: commit, rollback: rollback}
+}
+
+func (s *transactionStep) Commit(ctx context.Context) error {
+	if s.commit == nil {
+		return nil
+	}
+	return s.commit(ctx)
+}
+
+func (s *transactionStep) Rollback(ctx context.Context) error {
+	if s.rollback == nil {
+		return nil
+	}
+	return s.rollback(ctx)
+}
+
+// Transaction records the changes made during a transactional operation, like
+// a transactional Up, so they can all be committed or rolled back at the end.
+// It is safe for concurrent use.
+type Transaction struct {
+	mu    sync.Mutex
+	steps []TransactionStep
+}
+
+// Add records the specified step.
+func (t *Transaction) Add(step TransactionStep) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.steps = append(t.steps, step)
+}
+
+// Commit commits every step, in the order they were added.
+func (t *Transaction) Commit(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	errs := []error{}
+	for _, step := range t.steps {
+		if err := step.Commit(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	t.steps = nil
+	return NewMultiError(errs...).errorOrNil()
+}
+
+// Rollback rolls back every step, in the reverse order they were added.
+func (t *Transaction) Rollback(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	errs := []error{}
+	for i := len(t.steps) - 1; i >= 0; i-- {
+		if err := t.steps[i].Rollback(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	t.steps = nil
+	return NewMultiError(errs...).errorOrNil()
+}
+
+type transactionKey struct{}
+
+// WithTransaction returns a copy of the specified context carrying the
+// specified transaction, so the services record their changes in it.
+func WithTransaction(ctx context.Context, t *Transaction) context.Context {
+	return context.WithValue(ctx, transactionKey{}, t)
+}
+
+// TransactionFromContext returns the transaction carried by the specified
+// context, or nil if the operation is not transactional.
+func TransactionFromContext(ctx context.Context) *Transaction {
+	t, _ := ctx.Value(transactionKey{}).(*Transaction)
+	return t
+}
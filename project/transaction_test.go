@@ -0,0 +1,67 @@
+package project
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project/options"
+	"github.com/hyperhq/libcompose/yaml"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestTransactionalUp(t *testing.T) {
+	newProject := func(fail string) (*Project, *TestHookServiceFactory) {
+		factory := &TestHookServiceFactory{hooks: map[string]func(ctx context.Context, operation string) error{}}
+		p := NewProject(nil, &Context{
+			ServiceFactory: factory,
+		})
+		factory.project = p
+		p.ServiceConfigs = config.NewServiceConfigs()
+		p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+		p.ServiceConfigs.Add("app", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "db"}}})
+		p.ServiceConfigs.Add("web", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "app"}}})
+		for _, name := range []string{"db", "app", "web"} {
+			name := name
+			factory.hooks[name] = func(ctx context.Context, operation string) error {
+				if name == fail {
+					return fmt.Errorf("%s failed", name)
+				}
+				if tx := TransactionFromContext(ctx); tx != nil {
+					tx.Add(NewTransactionStep(func(ctx context.Context) error {
+						factory.record("commit", name)
+						return nil
+					}, func(ctx context.Context) error {
+						factory.record("rollback", name)
+						return nil
+					}))
+				}
+				return nil
+			}
+		}
+		return p, factory
+	}
+
+	p, factory := newProject("")
+	if err := p.UpContext(context.Background(), options.Up{Transactional: true}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"db", "app", "web"}, factory.called("commit"))
+	assert.Empty(t, factory.called("rollback"))
+
+	p, factory = newProject("web")
+	err := p.UpContext(context.Background(), options.Up{Transactional: true})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	assert.Empty(t, factory.called("commit"))
+	assert.Equal(t, []string{"app", "db"}, factory.called("rollback"))
+
+	p, factory = newProject("web")
+	if err := p.UpContext(context.Background(), options.Up{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	assert.Empty(t, factory.called("commit"))
+	assert.Empty(t, factory.called("rollback"))
+}
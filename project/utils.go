@@ -7,7 +7,7 @@ import (
 )
 
 // DefaultDependentServices return the dependent services (as an array of ServiceRelationship)
-// for the specified project and service. It looks for : links, volumesFrom, net and ipc configuration.
+// for the specified project and service. It looks for : links, depends_on, volumesFrom, net and ipc configuration.
 func DefaultDependentServices(p *Project, s Service) []ServiceRelationship {
 	config := s.Config()
 	if config == nil {
@@ -19,6 +19,12 @@ func DefaultDependentServices(p *Project, s Service) []ServiceRelationship {
 		result = append(result, NewServiceRelationship(link, RelTypeLink))
 	}
 
+	for _, dependsOn := range config.DependsOn {
+		relationship := NewServiceRelationship(dependsOn.Service, RelTypeDependsOn)
+		relationship.Condition = DependencyCondition(dependsOn.Condition)
+		result = append(result, relationship)
+	}
+
 	/*
 		for _, volumesFrom := range config.VolumesFrom {
 			result = append(result, NewServiceRelationship(volumesFrom, RelTypeVolumesFrom))
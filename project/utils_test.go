@@ -0,0 +1,24 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependsOnRelationships(t *testing.T) {
+	service := &TestService{
+		config: &config.ServiceConfig{
+			Links:     yaml.MaporColonSlice{"db:database"},
+			DependsOn: yaml.DependsOn{{Service: "cache"}},
+		},
+	}
+
+	relationships := DefaultDependentServices(nil, service)
+	assert.Equal(t, []ServiceRelationship{
+		{Target: "db", Alias: "database", Type: RelTypeLink},
+		{Target: "cache", Alias: "cache", Type: RelTypeDependsOn},
+	}, relationships)
+}
@@ -0,0 +1,182 @@
+package project
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/hyperhq/libcompose/project/options"
+	log "github.com/sirupsen/logrus"
+)
+
+// ContainerExit holds the exit code of a container of a service.
+type ContainerExit struct {
+	Service  string
+	Name     string
+	ExitCode int
+}
+
+type containerExitResult struct {
+	name string
+	exit *ContainerExit
+	err  error
+}
+
+// startRecord holds the names of the containers started while it is
+// recorded by the project.
+type startRecord struct {
+	mu      sync.Mutex
+	started map[string]bool
+}
+
+func (r *startRecord) add(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started[name] = true
+}
+
+func (r *startRecord) has(name string) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.started[name]
+}
+
+// recordStarts makes the project record the containers it starts, until
+// stopRecordingStarts is called.
+func (p *Project) recordStarts() *startRecord {
+	r := &startRecord{started: map[string]bool{}}
+
+	p.followerMu.Lock()
+	defer p.followerMu.Unlock()
+	p.starts = r
+	return r
+}
+
+// stopRecordingStarts stops recording the containers the project starts.
+func (p *Project) stopRecordingStarts(r *startRecord) {
+	p.followerMu.Lock()
+	defer p.followerMu.Unlock()
+	if p.starts == r {
+		p.starts = nil
+	}
+}
+
+// Wait blocks until a running container of the specified services, or of any
+// service if none is specified, exits and returns its exit code. The
+// containers whose wait failed are ignored, unless the wait of every
+// container failed.
+func (p *Project) Wait(ctx context.Context, services ...string) (*ContainerExit, error) {
+	return p.wait(ctx, nil, services...)
+}
+
+// wait is like Wait, but also waits for the containers which are not running
+// anymore if they were recorded in starts, so their exit code is returned.
+func (p *Project) wait(ctx context.Context, starts *startRecord, services ...string) (*ContainerExit, error) {
+	names, err := p.selectedServices(services)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan containerExitResult)
+	count := 0
+	for _, name := range names {
+		service, err := p.CreateService(name)
+		if err != nil {
+			return nil, err
+		}
+
+		containers, err := service.ContainersContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, container := range containers {
+			// The containers which exited earlier would be reported at once,
+			// unless they exited since they were started by the operation
+			// waiting for them.
+			running, err := container.IsRunningContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if !running && !starts.has(container.Name()) {
+				continue
+			}
+
+			count++
+			go func(name string, container Container) {
+				exitCode, err := container.Wait(ctx)
+				result := containerExitResult{name: container.Name(), err: err}
+				if err == nil {
+					result.exit = &ContainerExit{
+						Service:  name,
+						Name:     container.Name(),
+						ExitCode: exitCode,
+					}
+				}
+				select {
+				case results <- result:
+				case <-ctx.Done():
+				}
+			}(name, container)
+		}
+	}
+
+	if count == 0 {
+		return nil, fmt.Errorf("No running container to wait for")
+	}
+
+	var lastErr error
+	for ; count > 0; count-- {
+		select {
+		case result := <-results:
+			if result.err == nil {
+				return result.exit, nil
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			log.Warnf("Failed to wait for %s: %v", result.name, result.err)
+			lastErr = result.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return nil, fmt.Errorf("Failed to wait for the containers: %v", lastErr)
+}
+
+// waitAndStop waits for a container of the options.ExitCodeFrom service, or
+// of any of the specified services, to exit, then stops the services. The
+// containers recorded in starts are waited for even if they already exited.
+// It returns an *ExitError if the container exited with a non-zero code.
+func (p *Project) waitAndStop(ctx context.Context, options options.Up, starts *startRecord, services ...string) error {
+	waitFor := services
+	if options.ExitCodeFrom != "" {
+		waitFor = []string{options.ExitCodeFrom}
+	}
+
+	exit, err := p.wait(ctx, starts, waitFor...)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("%s exited with code %d, stopping the project", exit.Name, exit.ExitCode)
+	if err := p.StopContext(ctx, 10, services...); err != nil {
+		return err
+	}
+
+	if exit.ExitCode != 0 {
+		return &ExitError{
+			Service:  exit.Service,
+			Name:     exit.Name,
+			ExitCode: exit.ExitCode,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package project
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperhq/libcompose/config"
+	"github.com/hyperhq/libcompose/project/options"
+	"github.com/hyperhq/libcompose/yaml"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func newWaitProject() (*Project, *TestHookServiceFactory) {
+	factory := &TestHookServiceFactory{
+		containers: map[string][]Container{
+			"db":  {&TestConditionContainer{running: true}},
+			"job": {&TestConditionContainer{exitCode: 3}},
+		},
+	}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+	p.ServiceConfigs.Add("job", &config.ServiceConfig{DependsOn: yaml.DependsOn{{Service: "db"}}})
+	return p, factory
+}
+
+func TestWait(t *testing.T) {
+	p, _ := newWaitProject()
+
+	exit, err := p.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &ContainerExit{Service: "job", Name: "test", ExitCode: 3}, exit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.Wait(ctx, "db"); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestWaitIgnoresExitedAndFailed(t *testing.T) {
+	factory := &TestHookServiceFactory{
+		containers: map[string][]Container{
+			"db":  {&TestConditionContainer{name: "db_1", exited: true}},
+			"job": {&TestConditionContainer{name: "job_1", waitErr: errors.New("connection reset")}, &TestConditionContainer{name: "job_2", exitCode: 1}},
+		},
+	}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+	p.ServiceConfigs.Add("job", &config.ServiceConfig{})
+
+	for i := 0; i < 10; i++ {
+		exit, err := p.Wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, &ContainerExit{Service: "job", Name: "job_2", ExitCode: 1}, exit)
+	}
+
+	if _, err := p.Wait(context.Background(), "db"); err == nil {
+		t.Fatal("expected an error without running container")
+	}
+
+	factory.containers["job"] = factory.containers["job"][:1]
+	if _, err := p.Wait(context.Background(), "job"); err == nil {
+		t.Fatal("expected an error when every wait failed")
+	}
+}
+
+func TestUpExitCodeFrom(t *testing.T) {
+	p, factory := newWaitProject()
+
+	err := p.UpContext(context.Background(), options.Up{ExitCodeFrom: "job"})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("expected an ExitError, got %v", err)
+	}
+	assert.Equal(t, 3, exitErr.ExitCode)
+	assert.Equal(t, "job", exitErr.Service)
+	assert.Equal(t, []string{"db", "job"}, factory.called("up"))
+	assert.ElementsMatch(t, []string{"db", "job"}, factory.called("stop"))
+
+	if err := p.UpContext(context.Background(), options.Up{ExitCodeFrom: "web"}); err == nil {
+		t.Fatal("expected an error for an undefined service")
+	}
+}
+
+func TestUpExitCodeFromExited(t *testing.T) {
+	// job exits before the wait begins, and db keeps running.
+	job := &TestConditionContainer{name: "job_1", exited: true, exitCode: 3}
+	factory := &TestHookServiceFactory{
+		containers: map[string][]Container{
+			"db":  {&TestConditionContainer{name: "db_1", running: true}},
+			"job": {job},
+		},
+	}
+	p := NewProject(nil, &Context{
+		ServiceFactory: factory,
+	})
+	factory.project = p
+	p.ServiceConfigs = config.NewServiceConfigs()
+	p.ServiceConfigs.Add("db", &config.ServiceConfig{})
+	p.ServiceConfigs.Add("job", &config.ServiceConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := p.UpContext(ctx, options.Up{ExitCodeFrom: "job"})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("expected an ExitError, got %v", err)
+	}
+	assert.Equal(t, 3, exitErr.ExitCode)
+
+	// A container which exited before the up, and was not started by it, is
+	// not waited for.
+	factory.upToDate = map[string]bool{"job": true}
+	if err := p.UpContext(ctx, options.Up{ExitCodeFrom: "job"}); err == nil {
+		t.Fatal("expected an error without running container")
+	}
+}
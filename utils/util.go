@@ -11,8 +11,20 @@ import (
 // InParallel holds a pool and a waitgroup to execute tasks in parallel and to be able
 // to wait for completion of all tasks.
 type InParallel struct {
-	wg   sync.WaitGroup
-	pool sync.Pool
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	errors []error
+	slots  chan struct{}
+}
+
+// NewInParallel returns an InParallel that runs at most limit tasks at the same
+// time. A limit lower than 1 means no limit, like the zero value.
+func NewInParallel(limit int) *InParallel {
+	i := &InParallel{}
+	if limit > 0 {
+		i.slots = make(chan struct{}, limit)
+	}
+	return i
 }
 
 // Add runs the specified task in parallel and adds it to the waitGroup.
@@ -21,21 +33,37 @@ func (i *InParallel) Add(task func() error) {
 
 	go func() {
 		defer i.wg.Done()
+		if i.slots != nil {
+			i.slots <- struct{}{}
+			defer func() { <-i.slots }()
+		}
 		err := task()
 		if err != nil {
-			i.pool.Put(err)
+			i.mu.Lock()
+			i.errors = append(i.errors, err)
+			i.mu.Unlock()
 		}
 	}()
 }
 
 // Wait waits for all tasks to complete and returns the latest error encountered if any.
+// Every error encountered is available through Errors.
 func (i *InParallel) Wait() error {
 	i.wg.Wait()
-	obj := i.pool.Get()
-	if err, ok := obj.(error); ok {
-		return err
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.errors) == 0 {
+		return nil
 	}
-	return nil
+	return i.errors[len(i.errors)-1]
+}
+
+// Errors returns every error encountered by the tasks, in the order they
+// completed. It should be called after Wait.
+func (i *InParallel) Errors() []error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]error{}, i.errors...)
 }
 
 // ConvertByJSON converts a struct (src) to another one (target) using json marshalling/unmarshalling.
@@ -83,3 +83,38 @@ func TestInParallelError(t *testing.T) {
 		}
 	}
 }
+
+func TestInParallelLimit(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		running int
+		maxSeen int
+		limit   = 2
+		release = make(chan struct{})
+	)
+	tasks := NewInParallel(limit)
+	for i := 0; i < 6; i++ {
+		tasks.Add(func() error {
+			mu.Lock()
+			running++
+			if running > maxSeen {
+				maxSeen = running
+			}
+			mu.Unlock()
+			<-release
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil
+		})
+	}
+	for i := 0; i < 6; i++ {
+		release <- struct{}{}
+	}
+	if err := tasks.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if maxSeen > limit {
+		t.Fatalf("expected at most %d tasks at the same time, got %d", limit, maxSeen)
+	}
+}
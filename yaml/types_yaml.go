@@ -1,6 +1,7 @@
 package yaml
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"sort"
@@ -286,3 +287,101 @@ func toStrings(s []interface{}) ([]string, error) {
 	}
 	return r, nil
 }
+
+// Dependency holds the name of a service dependency and the condition the
+// dependency has to reach, if any.
+type Dependency struct {
+	Service   string
+	Condition string `yaml:"condition,omitempty"`
+}
+
+// DependsOn represents a list of service dependencies. It gets unmarshal from
+// a YAML list of service names or from a YAML map of service names to their
+// condition.
+type DependsOn []Dependency
+
+// Services returns the name of the services of the dependencies.
+func (d DependsOn) Services() []string {
+	services := make([]string, 0, len(d))
+	for _, dependency := range d {
+		services = append(services, dependency.Service)
+	}
+	return services
+}
+
+// MarshalYAML implements the Marshaller interface.
+func (d DependsOn) MarshalYAML() (tag string, value interface{}, err error) {
+	hasCondition := false
+	for _, dependency := range d {
+		if dependency.Condition != "" {
+			hasCondition = true
+			break
+		}
+	}
+	if !hasCondition {
+		return "", d.Services(), nil
+	}
+	dependencyMap := make(map[string]map[string]string)
+	for _, dependency := range d {
+		dependencyMap[dependency.Service] = map[string]string{"condition": dependency.Condition}
+	}
+	return "", dependencyMap, nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, with the same
+// representation as MarshalYAML.
+func (d DependsOn) MarshalJSON() ([]byte, error) {
+	_, value, err := d.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// UnmarshalYAML implements the Unmarshaller interface.
+func (d *DependsOn) UnmarshalYAML(tag string, value interface{}) error {
+	switch value := value.(type) {
+	case []interface{}:
+		services, err := toStrings(value)
+		if err != nil {
+			return err
+		}
+		dependencies := make(DependsOn, 0, len(services))
+		for _, service := range services {
+			dependencies = append(dependencies, Dependency{Service: service})
+		}
+		*d = dependencies
+	case map[interface{}]interface{}:
+		dependencies := make(DependsOn, 0, len(value))
+		for k, v := range value {
+			service, ok := k.(string)
+			if !ok {
+				return fmt.Errorf("Cannot unmarshal '%v' of type %T into a string value", k, k)
+			}
+			dependency := Dependency{Service: service}
+			switch v := v.(type) {
+			case map[interface{}]interface{}:
+				if condition, ok := v["condition"]; ok {
+					if dependency.Condition, ok = condition.(string); !ok {
+						return fmt.Errorf("Cannot unmarshal '%v' of type %T into a string value", condition, condition)
+					}
+				}
+			case nil:
+			default:
+				return fmt.Errorf("Failed to unmarshal DependsOn: %#v", v)
+			}
+			dependencies = append(dependencies, dependency)
+		}
+		sort.Sort(byService(dependencies))
+		*d = dependencies
+	default:
+		return fmt.Errorf("Failed to unmarshal DependsOn: %#v", value)
+	}
+	return nil
+}
+
+type byService DependsOn
+
+func (s byService) Len() int           { return len(s) }
+func (s byService) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byService) Less(i, j int) bool { return s[i].Service < s[j].Service }
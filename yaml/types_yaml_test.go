@@ -276,3 +276,45 @@ nofile:
 		assert.Equal(t, ulimit.expected, actual, "should be equal")
 	}
 }
+
+type StructDependsOn struct {
+	DependsOn DependsOn `yaml:"depends_on,omitempty"`
+}
+
+func TestDependsOnYaml(t *testing.T) {
+	dependsOns := []struct {
+		yaml     string
+		expected DependsOn
+	}{
+		{
+			yaml:     `depends_on: [db, cache]`,
+			expected: DependsOn{{Service: "db"}, {Service: "cache"}},
+		},
+		{
+			yaml: `depends_on:
+  db:
+    condition: service_healthy
+  cache:
+    condition: service_started`,
+			expected: DependsOn{
+				{Service: "cache", Condition: "service_started"},
+				{Service: "db", Condition: "service_healthy"},
+			},
+		},
+	}
+
+	for _, dependsOn := range dependsOns {
+		s := StructDependsOn{}
+		err := yaml.Unmarshal([]byte(dependsOn.yaml), &s)
+		assert.Nil(t, err)
+		assert.Equal(t, dependsOn.expected, s.DependsOn)
+
+		d, err := yaml.Marshal(&s)
+		assert.Nil(t, err)
+
+		s2 := StructDependsOn{}
+		err = yaml.Unmarshal(d, &s2)
+		assert.Nil(t, err)
+		assert.Equal(t, dependsOn.expected, s2.DependsOn)
+	}
+}